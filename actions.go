@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// actionAliases are the names of the server actions in the flags, e.g. "--action describe".
+var actionAliases = map[string]string{
+	"classify": actionDetermineCommodityCodes,
+	"describe": actionEnrichDescription,
+	"origin":   actionDetermineOrigin,
+}
+
+// ActionResult is the outcome of an action other than the classification, e.g. the enriched description of the item.
+type ActionResult struct {
+	Status string         `json:"status"`
+	Error  *string        `json:"error,omitempty"`
+	Output map[string]any `json:"output,omitempty"`
+}
+
+// requestedActions are the server names of the actions requested for every item, the action of the --action flag
+// followed by the --extra-actions.
+var requestedActions = []string{actionDetermineCommodityCodes}
+
+// parseActions returns the server names of the action of the --action flag and the --extra-actions, e.g. "describe"
+// and "classify,origin". The names without an alias are sent as they are, so the actions the server adds later can be
+// requested without a new release.
+func parseActions(action, extraActions string) ([]string, error) {
+	if strings.TrimSpace(action) == "" {
+		return nil, fmt.Errorf("action flag must not be empty")
+	}
+
+	return parseActionList(action + "," + extraActions), nil
+}
+
+// parseActionList returns the server names of the comma-separated actions, e.g. "classify, describe".
+func parseActionList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		// The actions are given either by the alias or by the server name.
+		if aliased, ok := actionAliases[strings.ToLower(name)]; ok {
+			name = aliased
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// itemActions returns the actions requested for any of the items, the requested actions of the run first. The items
+// request other actions with the actions column of the input.
+func itemActions(items []ImportItemRequest) []string {
+	var actions []string
+	for _, item := range items {
+		for _, action := range item.Actions {
+			if !slices.Contains(actions, action.Name) {
+				actions = append(actions, action.Name)
+			}
+		}
+	}
+	slices.SortStableFunc(actions, func(a, b string) int {
+		return requestedIndex(a) - requestedIndex(b)
+	})
+
+	return actions
+}
+
+// requestedIndex returns the index of the action in the requested actions of the run, the other actions follow them.
+func requestedIndex(action string) int {
+	if i := slices.Index(requestedActions, action); i >= 0 {
+		return i
+	}
+
+	return len(requestedActions)
+}
+
+// classifies reports whether the commodity codes are among the actions, they are not with e.g. "--action describe".
+func classifies(actions []string) bool {
+	return slices.Contains(actions, actionDetermineCommodityCodes)
+}
+
+// resultActions returns the actions other than the classification, whose results are written to the action columns.
+func resultActions(actions []string) []string {
+	var results []string
+	for _, action := range actions {
+		if action != actionDetermineCommodityCodes {
+			results = append(results, action)
+		}
+	}
+
+	return results
+}
+
+// itemOutcome returns the status of the item over its own requested actions, which the response item holds: failed if
+// any of them failed, processed if all of them are processed, and "" while any of them is unfinished. The error message
+// joins the errors of the failed actions, named by the action if the item requested several, e.g. for the dashboard and
+// the run log.
+func itemOutcome(item ImportItemResponse, unknownError string) (status, message string) {
+	var errs []string
+	processed := len(item.Actions) > 0
+	for _, action := range item.Actions {
+		switch action.Status {
+		case ImportItemStatusProcessed:
+		case ImportItemStatusFailed:
+			message := unknownError
+			if action.Error != nil {
+				message = *action.Error
+			}
+			if len(item.Actions) > 1 {
+				message = actionName(action.Name) + ": " + message
+			}
+			errs = append(errs, message)
+		default:
+			processed = false
+		}
+	}
+
+	switch {
+	case len(errs) > 0:
+		return ImportItemStatusFailed, strings.Join(errs, "; ")
+	case processed:
+		return ImportItemStatusProcessed, ""
+	default:
+		return "", ""
+	}
+}
+
+// actionName returns the name of the server action in the outputs, e.g. "describe" for "enrichDescription".
+func actionName(action string) string {
+	for alias, name := range actionAliases {
+		if name == action {
+			return alias
+		}
+	}
+
+	return action
+}
+
+// cell returns the text written to the result column of the action. An output with several values is written as
+// "name: value" pairs, e.g. "countryOfOrigin: CN; confidence: 0.8".
+func (r ActionResult) cell() string {
+	if r.Status != ImportItemStatusProcessed {
+		// The messages of the unfinished and failed actions are the same as of the commodity codes.
+		return TerritoryResult{Status: r.Status, Error: r.Error}.cell()
+	}
+	if len(r.Output) == 1 {
+		for _, value := range r.Output {
+			return formatOutputValue(value)
+		}
+	}
+
+	keys := make([]string, 0, len(r.Output))
+	for key := range r.Output {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = key + ": " + formatOutputValue(r.Output[key])
+	}
+
+	return strings.Join(values, "; ")
+}
+
+// formatOutputValue returns the cell text of a value of the action output. The lists are joined with commas.
+func formatOutputValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = formatOutputValue(item)
+		}
+		return strings.Join(values, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeActions writes the results of the actions other than the classification to the row of the item.
+func (l resultLayout) writeActions(row []string, result *ItemResult) {
+	for action, actionResult := range result.Actions {
+		if i, ok := l.actionColumns[action]; ok {
+			row[i] = actionResult.cell()
+		}
+	}
+}
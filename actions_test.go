@@ -0,0 +1,68 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseActions(t *testing.T) {
+	tests := []struct {
+		name         string
+		action       string
+		extraActions string
+		want         []string
+		wantErr      bool
+	}{
+		{"default", "classify", "", []string{actionDetermineCommodityCodes}, false},
+		{"alias", "describe", "", []string{actionEnrichDescription}, false},
+		{"extra actions", "classify", "describe,origin", []string{actionDetermineCommodityCodes, actionEnrichDescription, actionDetermineOrigin}, false},
+		{"spaces and case", "Classify", " describe , ORIGIN ", []string{actionDetermineCommodityCodes, actionEnrichDescription, actionDetermineOrigin}, false},
+		{"server name", "classify", "determineHazards", []string{actionDetermineCommodityCodes, "determineHazards"}, false},
+		{"alias and server name of the same action", "classify", "determineCommodityCodes,classify", []string{actionDetermineCommodityCodes}, false},
+		{"empty extra actions", "classify", ",,", []string{actionDetermineCommodityCodes}, false},
+		{"empty action", " ", "describe", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseActions(tt.action, tt.extraActions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseActions() error = %v, want error %t", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseActions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemOutcome(t *testing.T) {
+	failure := "no code found"
+	tests := []struct {
+		name        string
+		actions     []ActionResponse
+		wantStatus  string
+		wantMessage string
+	}{
+		{"no actions", nil, "", ""},
+		{"processed", []ActionResponse{{Name: actionDetermineCommodityCodes, Status: ImportItemStatusProcessed}}, ImportItemStatusProcessed, ""},
+		{"own action processed", []ActionResponse{{Name: actionEnrichDescription, Status: ImportItemStatusProcessed}}, ImportItemStatusProcessed, ""},
+		{"failed", []ActionResponse{{Name: actionDetermineCommodityCodes, Status: ImportItemStatusFailed, Error: &failure}}, ImportItemStatusFailed, failure},
+		{"failed without error", []ActionResponse{{Name: actionDetermineCommodityCodes, Status: ImportItemStatusFailed}}, ImportItemStatusFailed, "unknown error"},
+		{"unfinished action", []ActionResponse{
+			{Name: actionDetermineCommodityCodes, Status: ImportItemStatusProcessed},
+			{Name: actionEnrichDescription, Status: ImportItemStatusProcessing},
+		}, "", ""},
+		{"second action failed", []ActionResponse{
+			{Name: actionDetermineCommodityCodes, Status: ImportItemStatusProcessed},
+			{Name: actionEnrichDescription, Status: ImportItemStatusFailed, Error: &failure},
+		}, ImportItemStatusFailed, "describe: " + failure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := itemOutcome(ImportItemResponse{ID: "1", Actions: tt.actions}, "unknown error")
+			if status != tt.wantStatus || message != tt.wantMessage {
+				t.Errorf("itemOutcome() = %q, %q, want %q, %q", status, message, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+)
+
+// anonymizedIDs maps the item IDs of the input to the opaque tokens sent instead of them with --anonymize, so the
+// internal article numbers don't leave the network. The tokens are numbered by the order of the items, so a resumed
+// run gives the items the same tokens.
+type anonymizedIDs struct {
+	tokens map[string]string // by the item ID
+	ids    map[string]string // by the token
+}
+
+func newAnonymizedIDs(items []ImportItemRequest) *anonymizedIDs {
+	a := &anonymizedIDs{
+		tokens: make(map[string]string, len(items)),
+		ids:    make(map[string]string, len(items)),
+	}
+	for i, item := range items {
+		token := fmt.Sprintf("item-%d", i+1)
+		a.tokens[item.ID] = token
+		a.ids[token] = item.ID
+	}
+
+	return a
+}
+
+// request returns the copy of the import with the tokens instead of the item IDs.
+func (a *anonymizedIDs) request(imp ImportRequest) ImportRequest {
+	if a == nil {
+		return imp
+	}
+
+	items := make([]ImportItemRequest, len(imp.ImportItems))
+	for i, item := range imp.ImportItems {
+		item.ID = a.tokens[item.ID]
+		items[i] = item
+	}
+
+	return ImportRequest{ImportItems: items}
+}
+
+// restore replaces the tokens of the response with the item IDs, before the results are written.
+func (a *anonymizedIDs) restore(response *ImportResponse) {
+	if a == nil || response == nil {
+		return
+	}
+
+	for i, item := range response.ImportItems {
+		if id, ok := a.ids[item.ID]; ok {
+			response.ImportItems[i].ID = id
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+// apiVersionOnce selects the API version once per process, so the later runs of the process, e.g. the jobs of serve
+// and watch, don't ask the server again.
+var apiVersionOnce sync.Once
+
+// setupAPIVersion selects the API version the requests are sent to. Without --api-version, the newest version both
+// the CLI and the server support is used. A server that can't be asked is left at the default version, its requests
+// report the actual error. The v2 import schema is not published yet, so v1 is the only supported version until it is.
+func setupAPIVersion(ctx context.Context) {
+	apiVersionOnce.Do(func() {
+		if apiVersion != "" {
+			if !slices.Contains(customs.SupportedAPIVersions, apiVersion) {
+				fatalf("api-version flag value %q is not supported, the supported versions are %s", apiVersion, strings.Join(customs.SupportedAPIVersions, ", "))
+			}
+			return
+		}
+
+		negotiated, err := newAPIClient(url, apiKey).NegotiateAPIVersion(ctx)
+		if errors.Is(err, customs.ErrNotSupported) {
+			fatal(err)
+		}
+		if err != nil {
+			slog.Debug("the API version can't be detected, the default version is used", "error", err)
+			return
+		}
+		slog.Debug("API version detected", "version", negotiated)
+		apiVersion = negotiated
+	})
+}
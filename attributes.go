@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// resultHeadings are the untranslated headings of the result columns other than "result ...", which are not item
+// attributes when the input is a result file of an earlier run.
+var resultHeadings = []string{"compliance review", "changed?", "models agree", "result updated at", "warnings"}
+
+// attributeColumns returns the headings of the input columns that are not read as the item fields by the column index,
+// they are sent as the attributes of the items with --attributes, e.g. "material" or "brand". The result columns of an
+// earlier run and the columns without a heading are left out.
+func attributeColumns(headings []string, matched []matchedColumn) map[int]string {
+	attributes := map[int]string{}
+	for i, heading := range headings {
+		heading = strings.TrimSpace(heading)
+		if heading == "" || isResultHeading(heading) {
+			continue
+		}
+		isMatched := false
+		for _, column := range matched {
+			if column.index != nil && *column.index == i {
+				isMatched = true
+				break
+			}
+		}
+		if !isMatched {
+			attributes[i] = heading
+		}
+	}
+
+	return attributes
+}
+
+// checkUnknownColumns returns an error naming the input columns that are neither the columns of the items, the columns
+// of the description template nor the result columns of an earlier run, with --strict-columns. The closest column of a misspelled heading, e.g.
+// "descriptoin", is suggested.
+func checkUnknownColumns(headings []string) error {
+	names := make([]string, 0, len(headingAliases))
+	for name := range headingAliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var unknown []string
+	for _, heading := range headings {
+		heading = strings.TrimSpace(heading)
+		if heading == "" || isResultHeading(heading) || isTemplateColumn(heading) || slices.ContainsFunc(names, func(name string) bool {
+			return matchesHeading(heading, name)
+		}) {
+			continue
+		}
+		if closest := closestName(heading, names); closest != "" {
+			unknown = append(unknown, trf("%q (did you mean %q?)", heading, closest))
+		} else {
+			unknown = append(unknown, strconv.Quote(heading))
+		}
+	}
+	if len(unknown) > 0 {
+		return errors.New(trf("the input has unknown columns: %s", strings.Join(unknown, ", ")))
+	}
+
+	return nil
+}
+
+// isResultHeading reports whether the heading is of a result column in any of the languages, e.g. "result EU",
+// "Ergebnis describe" or "Warenbezeichnung NO".
+func isResultHeading(heading string) bool {
+	keys := slices.Clone(resultHeadings)
+	for _, territory := range allowedCustomsTerritories {
+		for _, detail := range codeDetails {
+			keys = append(keys, detail.key(territory))
+		}
+	}
+	// The results of the territories, the models and the actions start with "result ".
+	prefixes := []string{"result "}
+	for _, messages := range translations {
+		prefixes = append(prefixes, strings.TrimSuffix(messages["result %s"], "%s"))
+		for _, key := range keys {
+			if translated, ok := messages[key]; ok && strings.EqualFold(heading, translated) {
+				return true
+			}
+		}
+	}
+	for _, key := range keys {
+		if strings.EqualFold(heading, key) {
+			return true
+		}
+	}
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(strings.ToLower(heading), strings.ToLower(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
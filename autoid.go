@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	autoIDRow  = "row"
+	autoIDHash = "hash"
+)
+
+// autoIDHeading is the heading of the id column appended to an input without it.
+const autoIDHeading = "id"
+
+// fillItemID writes the generated item ID to the blank id cell of the row, so the results can be written to the row and
+// the ID is part of the outputs. The row is padded to the id column if it is shorter.
+func fillItemID(values []string, idColumn, row int) []string {
+	for len(values) <= idColumn {
+		values = append(values, "")
+	}
+	if strings.TrimSpace(values[idColumn]) == "" {
+		values[idColumn] = generateItemID(values, idColumn, row)
+	}
+
+	return values
+}
+
+// generateItemID returns the ID of the row by the --auto-id flag. The row number is stable as long as the rows are not
+// reordered, the hash of the other values of the row as long as the row is not edited.
+func generateItemID(values []string, idColumn, row int) string {
+	if autoID != autoIDHash {
+		return fmt.Sprintf("row-%d", row)
+	}
+
+	h := sha256.New()
+	for i, value := range values {
+		if i != idColumn {
+			h.Write([]byte(strings.TrimSpace(value)))
+			h.Write([]byte{0})
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+// categoryVocabulary holds the categories and subcategories the server accepts, the items are validated against it
+// before the import is sent.
+type categoryVocabulary struct {
+	categories []CategoryResponse
+}
+
+var (
+	vocabulary     *categoryVocabulary
+	vocabularyOnce sync.Once
+)
+
+// loadCategoryVocabulary returns the vocabulary of the server for the input with the category column. It is fetched
+// once per run. Nil is returned if the server doesn't list the categories, the items are then validated by the server.
+func loadCategoryVocabulary(headings []string) *categoryVocabulary {
+	if getColumnIndex(headings, "category") == nil {
+		return nil
+	}
+	vocabularyOnce.Do(func() {
+		response, err := newAPIClient(url, apiKey).GetCategories(context.Background())
+		if errors.Is(err, customs.ErrNotSupported) {
+			slog.Debug("the server doesn't list the categories, they are not validated")
+			return
+		}
+		if err != nil {
+			warnf("the categories can't be fetched, they are not validated: %s", err)
+			return
+		}
+		vocabulary = &categoryVocabulary{categories: response.Categories}
+	})
+
+	return vocabulary
+}
+
+// check validates the category and the subcategory of the item. The values in a different case are replaced by the
+// names of the server, the unknown values are handled by the --on-invalid policy and reported with the closest name.
+func (v *categoryVocabulary) check(item *ImportItemRequest, source SourceLocation) error {
+	if item.Category == nil || strings.TrimSpace(*item.Category) == "" {
+		return nil
+	}
+	var names []string
+	var category *CategoryResponse
+	for i, c := range v.categories {
+		names = append(names, c.Name)
+		if strings.EqualFold(strings.TrimSpace(*item.Category), c.Name) {
+			category = &v.categories[i]
+		}
+	}
+	if category == nil {
+		return handleInvalidTerm("category", &item.Category, names, item.ID, source)
+	}
+	item.Category = &category.Name
+
+	if item.Subcategory == nil || strings.TrimSpace(*item.Subcategory) == "" {
+		return nil
+	}
+	for i, subcategory := range category.Subcategories {
+		if strings.EqualFold(strings.TrimSpace(*item.Subcategory), subcategory) {
+			item.Subcategory = &category.Subcategories[i]
+			return nil
+		}
+	}
+
+	return handleInvalidTerm("subcategory", &item.Subcategory, category.Subcategories, item.ID, source)
+}
+
+// handleInvalidTerm applies the --on-invalid policy to the value that is not in the vocabulary. The error suggests the
+// closest name of the vocabulary, if there is one, as the value is most likely misspelled.
+func handleInvalidTerm(column string, value **string, names []string, itemID string, source SourceLocation) error {
+	if onInvalid == onInvalidDrop {
+		warnf("%s: invalid %s %q for item %q is dropped", source, column, **value, itemID)
+		*value = nil
+		return nil
+	}
+
+	if closest := closestName(**value, names); closest != "" {
+		return errors.New(trf("invalid %s %q for item %q, did you mean %q?", column, **value, itemID, closest))
+	}
+
+	return errors.New(trf("invalid %s %q for item %q", column, **value, itemID))
+}
+
+// closestName returns the name with the smallest edit distance to the value, or an empty string if no name is close
+// enough to be a misspelling of the value.
+func closestName(value string, names []string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	closest, closestDistance := "", max(1, utf8.RuneCountInString(value)/3)+1
+	for _, name := range names {
+		if distance := editDistance(value, strings.ToLower(name)); distance < closestDistance {
+			closest, closestDistance = name, distance
+		}
+	}
+
+	return closest
+}
+
+// editDistance returns the Levenshtein distance of the strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "shirt", 5},
+		{"shirt", "", 5},
+		{"shirt", "shirt", 0},
+		{"shirt", "shirts", 1},
+		{"shrit", "shirt", 2},
+		{"apparel", "aparel", 1},
+		{"kitten", "sitting", 3},
+		{"bekleidung", "bekleidunq", 1},
+		{"möbel", "mobel", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
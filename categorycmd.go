@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/drotsolutions/customs-cli/customs"
+	"github.com/xuri/excelize/v2"
+)
+
+// categoriesSheet is the sheet of the template with the categories the dropdowns are filled from.
+const categoriesSheet = "Categories"
+
+// templateRows is the number of the template rows with the category dropdowns.
+const templateRows = 10000
+
+// templateHeadings are the headings of the input sheet of the template.
+var templateHeadings = []string{"id", "name", "description", "customs territories", "category", "subcategory", "country of origin", "gross mass", "net mass", "weight unit"}
+
+func runCategories(args []string) {
+	var template string
+	fs := newCommandFlagSet("categories")
+	fs.StringVar(&template, "template", "", "")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	List the categories and subcategories the server accepts, so the input files can be filled with them.
+
+	Options:
+		--template	write an input template to the excel file, with the dropdowns of the categories and subcategories
+		--help		display this help and exit
+
+	Example:
+		customs categories --api-key "yourApiKey" --template items.xlsx
+
+`)
+
+		os.Exit(0)
+	}
+
+	setupRun()
+	if template != "" {
+		if err := confirmOverwrite(template); err != nil {
+			fatal(err)
+		}
+	}
+
+	response, err := newAPIClient(url, apiKey).GetCategories(context.Background())
+	if errors.Is(err, customs.ErrNotSupported) {
+		fatal("the server doesn't list the categories")
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	for _, category := range response.Categories {
+		fmt.Println(category.Name)
+		for _, subcategory := range category.Subcategories {
+			fmt.Println("  " + subcategory)
+		}
+	}
+	if template == "" {
+		return
+	}
+	if err = writeCategoriesTemplate(template, response.Categories); err != nil {
+		fatal(err)
+	}
+	fmt.Fprintln(console, trf("The input template is written to: %q", template))
+}
+
+// writeCategoriesTemplate writes the workbook with the headings of the input and the dropdowns of the categories and
+// subcategories. The subcategory dropdown has the subcategories of all categories, the categories sheet lists the
+// subcategories of every category.
+func writeCategoriesTemplate(path string, categories []CategoryResponse) error {
+	file := excelize.NewFile()
+	defer func() {
+		_ = file.Close()
+	}()
+	if err := file.SetSheetRow(inputSheet, "A1", &templateHeadings); err != nil {
+		return err
+	}
+	if _, err := file.NewSheet(categoriesSheet); err != nil {
+		return err
+	}
+
+	header := []string{"category", "subcategories", "", "subcategory"}
+	if err := file.SetSheetRow(categoriesSheet, "A1", &header); err != nil {
+		return err
+	}
+	var subcategories []string
+	for i, category := range categories {
+		row := []string{category.Name, strings.Join(category.Subcategories, ", ")}
+		if err := file.SetSheetRow(categoriesSheet, fmt.Sprintf("A%d", i+2), &row); err != nil {
+			return err
+		}
+		for _, subcategory := range category.Subcategories {
+			if !slices.Contains(subcategories, subcategory) {
+				subcategories = append(subcategories, subcategory)
+			}
+		}
+	}
+	for i, subcategory := range subcategories {
+		if err := file.SetCellStr(categoriesSheet, fmt.Sprintf("D%d", i+2), subcategory); err != nil {
+			return err
+		}
+	}
+
+	dropdowns := []struct {
+		heading string
+		column  string
+		count   int
+	}{
+		{"category", "A", len(categories)},
+		{"subcategory", "D", len(subcategories)},
+	}
+	for _, dropdown := range dropdowns {
+		if dropdown.count == 0 {
+			continue
+		}
+		column, err := excelize.ColumnNumberToName(slices.Index(templateHeadings, dropdown.heading) + 1)
+		if err != nil {
+			return err
+		}
+		dv := excelize.NewDataValidation(true)
+		dv.SetSqref(fmt.Sprintf("%s2:%s%d", column, column, templateRows+1))
+		dv.SetSqrefDropList(fmt.Sprintf("%s!$%s$2:$%s$%d", categoriesSheet, dropdown.column, dropdown.column, dropdown.count+1))
+		if err = file.AddDataValidation(inputSheet, dv); err != nil {
+			return err
+		}
+	}
+
+	return file.SaveAs(path)
+}
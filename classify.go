@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// classifyInput is the name of the single item input in the messages.
+const classifyInput = "command line"
+
+func runClassify(args []string) {
+	var name, description, territories, category, countryOfOrigin, grossMass, netMass string
+	fs := newCommandFlagSet("classify")
+	fs.StringVar(&name, "name", "", "")
+	fs.StringVar(&description, "description", "", "")
+	fs.StringVar(&territories, "territories", "", "")
+	fs.StringVar(&category, "category", "", "")
+	fs.StringVar(&countryOfOrigin, "country-of-origin", "", "")
+	fs.StringVar(&grossMass, "gross-mass", "", "")
+	fs.StringVar(&netMass, "net-mass", "", "")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Determine the commodity codes of a single item and print them, without an input file.
+	Run without the flags in a terminal, the fields of the items are asked for one item after another.
+
+	Options:
+		--name			name of the item
+		--description		description of the item
+		--territories		comma-separated customs territories, e.g. eu,no (default are the territories of the account, or asked for)
+		--category		category of the item
+		--country-of-origin	country of origin of the item
+		--gross-mass		gross mass of the item
+		--net-mass		net mass of the item
+		--help			display this help and exit
+
+	Example:
+		customs classify --api-key "yourApiKey" --name "Shirt" --description "Cotton shirt with long sleeves" --territories eu,no
+
+`)
+
+		os.Exit(0)
+	}
+
+	if name == "" && description == "" && isInteractive() {
+		setupRun()
+		classifyInteractively()
+		return
+	}
+	if name == "" || description == "" {
+		fatal("please provide the name and description flags")
+	}
+	setupRun()
+
+	fields := map[string]string{
+		"name":                name,
+		"description":         description,
+		"customs territories": territories,
+		"category":            category,
+		"country of origin":   countryOfOrigin,
+		"gross mass":          grossMass,
+		"net mass":            netMass,
+	}
+	result, err := classifyItem(fields)
+	if err != nil {
+		fatal(err)
+	}
+	if !printClassification(result) {
+		os.Exit(1)
+	}
+}
+
+// classifyItem imports a single item with the input values by the heading and returns its result. The item is read
+// like a row of an input file, so it is validated and mapped the same way.
+func classifyItem(fields map[string]string) (*ItemResult, error) {
+	headings := []string{"id"}
+	row := []string{"1"}
+	for heading, value := range fields {
+		if value != "" {
+			headings = append(headings, heading)
+			row = append(row, value)
+		}
+	}
+
+	reader, err := newTableReader(classifyInput, "", sliceRows([][]string{headings, row}), nil)
+	if err != nil {
+		return nil, err
+	}
+	if getColumnIndex(headings, "customs territories") == nil && defaultCustomsTerritories == nil {
+		defaultCustomsTerritories, err = resolveCustomsTerritories(context.Background(), url, apiKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	input, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	var rows inputRows
+	rows.add(input.Values, getColumnIndex(headings, "id"))
+	doc, err := newResultDocument(reader, rows, itemActions([]ImportItemRequest{input.Item}))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := newAPIClient(url, apiKey)
+	// The single item doesn't need the progress dots.
+	client.OnPoll = nil
+	response, err := client.Import(ctx, ImportRequest{ImportItems: []ImportItemRequest{input.Item}}, timeout)
+	if err != nil && !errors.Is(err, ErrFailed) && !errors.Is(err, ErrNotProcessed) {
+		return nil, err
+	}
+	for _, item := range response.ImportItems {
+		if err = doc.add(item); err != nil {
+			return nil, err
+		}
+	}
+	result, ok := doc.results[input.Item.ID]
+	if !ok {
+		return nil, fmt.Errorf("the server returned no result for the item")
+	}
+
+	return result, nil
+}
+
+// printClassification prints the commodity codes or the errors of the item by the customs territory, and the results
+// of the other actions. It reports whether the codes of all territories and the other actions are processed.
+func printClassification(result *ItemResult) bool {
+	processed := true
+	for _, territory := range allowedCustomsTerritories {
+		territoryResult, ok := result.Territories[territory]
+		if !ok {
+			continue
+		}
+		cell := territoryResult.cell()
+		if territoryResult.Description != "" {
+			cell += " (" + territoryResult.Description + ")"
+		}
+		fmt.Printf("%s: %s\n", strings.ToUpper(territory), colorize(os.Stdout, statusColor(territoryResult.Status), cell))
+		if territoryResult.Status != ImportItemStatusProcessed {
+			processed = false
+		}
+	}
+	for _, action := range resultActions(requestedActions) {
+		actionResult, ok := result.Actions[action]
+		if !ok {
+			continue
+		}
+		fmt.Printf("%s: %s\n", actionName(action), colorize(os.Stdout, statusColor(actionResult.Status), actionResult.cell()))
+		if actionResult.Status != ImportItemStatusProcessed {
+			processed = false
+		}
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("%s\n", colorize(os.Stdout, colorYellow, tr("Warning: ")+warning))
+	}
+
+	return processed
+}
+
+// classifyInteractively asks for the fields of an item, prints its commodity codes and continues with the next item
+// until the operator is done. An invalid or failed item doesn't end the session.
+func classifyInteractively() {
+	for {
+		fields := map[string]string{}
+		for _, field := range []struct {
+			heading  string
+			question string
+			required bool
+		}{
+			{"name", tr("Name:"), true},
+			{"description", tr("Description:"), true},
+			{"customs territories", trf("Customs territories (%s):", strings.Join(allowedCustomsTerritories, ", ")), true},
+			{"category", tr("Category (optional):"), false},
+			{"country of origin", tr("Country of origin (optional):"), false},
+			{"gross mass", tr("Gross mass (optional):"), false},
+			{"net mass", tr("Net mass (optional):"), false},
+		} {
+			for {
+				answer, err := ask(field.question)
+				if err != nil {
+					return
+				}
+				if answer != "" || !field.required {
+					fields[field.heading] = answer
+					break
+				}
+			}
+		}
+
+		result, err := classifyItem(fields)
+		if err != nil {
+			slog.Error(err.Error())
+		} else {
+			printClassification(result)
+		}
+
+		answer, err := ask(tr("Classify another item? [y/N]:"))
+		if err != nil {
+			return
+		}
+		if !isYes(answer) {
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestImportIdempotencyKeys(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		// The first request fails, so it is retried.
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Location", "/api/v1/items/imports/1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	defaultRetries := retries
+	defer func() { retries = defaultRetries }()
+	retries = newRetrier(RetryConfig{StatusCodes: []int{http.StatusServiceUnavailable}, MaxAttempts: 2, Budget: 1})
+
+	request := ImportRequest{ImportItems: []ImportItemRequest{{ID: "1"}}}
+	for _, salt := range []string{"", "", "retry"} {
+		if _, err := sendImportRequest(context.Background(), request, salt, server.URL, "key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(keys) != 4 {
+		t.Fatalf("%d requests sent, want 4", len(keys))
+	}
+	if keys[0] == "" || keys[1] != keys[0] {
+		t.Errorf("the retried import is sent with the keys %q and %q, want the same key", keys[0], keys[1])
+	}
+	if keys[2] != keys[0] {
+		t.Errorf("the same items are sent again with the key %q, want %q", keys[2], keys[0])
+	}
+	if keys[3] == keys[0] {
+		t.Errorf("the salted import is sent with the key %q of the first import, want a new key", keys[3])
+	}
+}
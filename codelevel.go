@@ -0,0 +1,41 @@
+package main
+
+const (
+	codeLevelHS6     = "hs6"
+	codeLevelCN8     = "cn8"
+	codeLevelTARIC10 = "taric10"
+)
+
+// codeLevelDigits is the number of the digits of a commodity code at the level of the --code-level flag.
+var codeLevelDigits = map[string]int{
+	codeLevelHS6:     6,
+	codeLevelCN8:     8,
+	codeLevelTARIC10: 10,
+}
+
+// truncateCode returns the commodity code cut to the level of the --code-level flag, for the downstream systems that
+// accept only e.g. the 6-digit HS codes. The codes shorter than the level, e.g. the 8-digit Norwegian codes with
+// taric10, are returned as they are.
+func truncateCode(code string) string {
+	digits, ok := codeLevelDigits[codeLevel]
+	if !ok || len(code) <= digits {
+		return code
+	}
+
+	return code[:digits]
+}
+
+// truncateResult cuts the code of the result to the level of the --code-level flag. The description, the rates, the
+// supplementary unit and the nomenclature path returned by the server belong to the full code, so they are cleared
+// instead of being written next to a code they don't describe. The measures are kept, they still apply to the item and
+// flag it for the compliance review.
+func truncateResult(r *TerritoryResult) {
+	code := truncateCode(r.Code)
+	if code == r.Code {
+		return
+	}
+
+	r.Code = code
+	r.Description, r.DutyRate, r.VATRate, r.SupplementaryUnit = "", "", "", ""
+	r.NomenclaturePath = nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTruncateCode(t *testing.T) {
+	tests := []struct {
+		level string
+		code  string
+		want  string
+	}{
+		{"", "6205200000", "6205200000"},
+		{codeLevelHS6, "6205200000", "620520"},
+		{codeLevelCN8, "6205200000", "62052000"},
+		{codeLevelTARIC10, "6205200000", "6205200000"},
+		{codeLevelHS6, "62052000", "620520"},
+		{codeLevelTARIC10, "62052000", "62052000"},
+		{codeLevelCN8, "620520", "620520"},
+		{codeLevelHS6, "", ""},
+	}
+	t.Cleanup(func() { codeLevel = "" })
+	for _, tt := range tests {
+		codeLevel = tt.level
+		if got := truncateCode(tt.code); got != tt.want {
+			t.Errorf("truncateCode(%q) at level %q = %q, want %q", tt.code, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateResult(t *testing.T) {
+	full := TerritoryResult{
+		Code:              "6205200000",
+		Description:       "Men's shirts of cotton",
+		DutyRate:          "12%",
+		VATRate:           "25%",
+		SupplementaryUnit: "p/st",
+		Measures:          []MeasureResponse{{Type: "licence"}},
+		NomenclaturePath:  []string{"62", "6205", "620520", "62052000", "6205200000"},
+	}
+	tests := []struct {
+		level string
+		want  TerritoryResult
+	}{
+		{"", full},
+		{codeLevelTARIC10, full},
+		{codeLevelHS6, TerritoryResult{Code: "620520", Measures: full.Measures}},
+		{codeLevelCN8, TerritoryResult{Code: "62052000", Measures: full.Measures}},
+	}
+	t.Cleanup(func() { codeLevel = "" })
+	for _, tt := range tests {
+		codeLevel = tt.level
+		got := full
+		truncateResult(&got)
+		if got.Code != tt.want.Code || got.Description != tt.want.Description || got.DutyRate != tt.want.DutyRate ||
+			got.VATRate != tt.want.VATRate || got.SupplementaryUnit != tt.want.SupplementaryUnit ||
+			len(got.Measures) != len(tt.want.Measures) || !slices.Equal(got.NomenclaturePath, tt.want.NomenclaturePath) {
+			t.Errorf("truncateResult() at level %q = %+v, want %+v", tt.level, got, tt.want)
+		}
+	}
+}
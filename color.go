@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+// colorize returns the text in the color if the writer shows colors, e.g. the processed items in green, the failed
+// ones in red and the warnings in yellow.
+func colorize(w io.Writer, color, text string) string {
+	if !useColors(w) {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// useColors reports whether the writer is a terminal that shows colors. The colors are disabled by the NO_COLOR
+// environment variable (https://no-color.org) and on the dumb terminals.
+func useColors(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return isTerminal(w)
+}
+
+// statusColor returns the color of the status of an item or an import.
+func statusColor(status string) string {
+	switch status {
+	case ImportItemStatusProcessed, dashboardStatusDone:
+		return colorGreen
+	case ImportItemStatusFailed: // the same as dashboardStatusFailed
+		return colorRed
+	default:
+		return colorYellow
+	}
+}
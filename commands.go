@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+)
+
+// commands are run by the name given as the first argument, e.g. "customs stats input-file.xlsx". Without a command,
+// the items from the input file are imported.
+var commands = map[string]func(args []string){
+	"import":      runImport,
+	"classify":    runClassify,
+	"stats":       runStats,
+	"categories":  runCategories,
+	"report":      runReport,
+	"diff":        runDiff,
+	"usage":       runUsage,
+	"whoami":      runWhoami,
+	"ping":        runPing,
+	"watch":       runWatch,
+	"serve":       runServe,
+	"self-update": runSelfUpdate,
+}
+
+// commandFlags is the flag set of the command that is run, nil before the command parses its flags.
+var commandFlags *flag.FlagSet
+
+// newCommandFlagSet returns the flag set of the command. The global flags are part of it, so they can be given both
+// before and after the command name.
+func newCommandFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	flag.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	commandFlags = fs
+
+	return fs
+}
+
+// isFlagGiven reports whether the flag is given on the command line, before or after the command name, also with its
+// default value.
+func isFlagGiven(name string) bool {
+	given := false
+	visit := func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	}
+	flag.Visit(visit)
+	if commandFlags != nil {
+		commandFlags.Visit(visit)
+	}
+
+	return given
+}
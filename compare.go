@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseCompareModels returns the two models of the --compare-models flag, e.g. "m1,m2".
+func parseCompareModels(value string) ([]string, error) {
+	models := strings.Split(value, ",")
+	for i := range models {
+		models[i] = strings.TrimSpace(models[i])
+	}
+	if len(models) != 2 || models[0] == "" || models[1] == "" || models[0] == models[1] {
+		return nil, fmt.Errorf("compare-models flag must name two different models, e.g. m1,m2")
+	}
+
+	return models, nil
+}
+
+// withModel returns the copies of the imports with the model set on the actions of every item, overriding the model
+// column of the input.
+func withModel(imports []ImportRequest, model string) []ImportRequest {
+	modelImports := make([]ImportRequest, len(imports))
+	for i, imp := range imports {
+		items := make([]ImportItemRequest, len(imp.ImportItems))
+		for j, item := range imp.ImportItems {
+			actions := make([]ActionRequest, len(item.Actions))
+			for k, action := range item.Actions {
+				action.Parameters.Model = &model
+				actions[k] = action
+			}
+			item.Actions = actions
+			items[j] = item
+		}
+		modelImports[i] = ImportRequest{ImportItems: items}
+	}
+
+	return modelImports
+}
+
+// comparedImports returns the imports of the second model of --compare-models. Only the commodity codes are compared,
+// so the other actions of the items are left out instead of being run and charged again, and so are the items and the
+// imports without the commodity codes action.
+func comparedImports(imports []ImportRequest, model string) []ImportRequest {
+	var compared []ImportRequest
+	for _, imp := range withModel(imports, model) {
+		var items []ImportItemRequest
+		for _, item := range imp.ImportItems {
+			var actions []ActionRequest
+			for _, action := range item.Actions {
+				if action.Name == actionDetermineCommodityCodes {
+					actions = append(actions, action)
+				}
+			}
+			if len(actions) == 0 {
+				continue
+			}
+			item.Actions = actions
+			items = append(items, item)
+		}
+		if len(items) > 0 {
+			compared = append(compared, ImportRequest{ImportItems: items})
+		}
+	}
+
+	return compared
+}
+
+// writeAgreement writes to the "models agree" column whether the two models returned the same codes for all customs
+// territories that both of them have a code for.
+func (l resultLayout) writeAgreement(row []string, result *ItemResult) {
+	if l.agreementColumn < 0 {
+		return
+	}
+	compared, agreed := countAgreement(result)
+	switch {
+	case compared == 0:
+		row[l.agreementColumn] = ""
+	case agreed == compared:
+		row[l.agreementColumn] = trOutput("yes")
+	default:
+		row[l.agreementColumn] = trOutput("no")
+	}
+}
+
+// countAgreement returns the number of the codes of the item both models returned and the number of the same ones.
+func countAgreement(result *ItemResult) (compared, agreed int) {
+	for territory, second := range result.ComparedTerritories {
+		first, ok := result.Territories[territory]
+		if !ok || first.Code == "" || second.Code == "" {
+			continue
+		}
+		compared++
+		if first.Code == second.Code {
+			agreed++
+		}
+	}
+
+	return compared, agreed
+}
+
+// printAgreement prints the number of the codes the models of --compare-models agree on.
+func (d *ResultDocument) printAgreement() {
+	compared, agreed := 0, 0
+	for _, result := range d.results {
+		c, a := countAgreement(result)
+		compared += c
+		agreed += a
+	}
+	if compared == 0 {
+		fmt.Fprintln(console, tr("The models returned no codes to compare."))
+		return
+	}
+	message := trf("The models %s and %s agree on %d of %d codes (%.1f%%).", comparedModels[0], comparedModels[1],
+		agreed, compared, 100*float64(agreed)/float64(compared))
+	color := colorGreen
+	if agreed < compared {
+		color = colorYellow
+	}
+	fmt.Fprintf(console, "%s\n", colorize(console, color, message))
+}
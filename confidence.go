@@ -0,0 +1,50 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// formatConfidence returns the cell of the classification confidence of the code, e.g. "0.87". The cell is empty if the
+// server doesn't return the confidence.
+func formatConfidence(r *TerritoryResult) string {
+	if r.Confidence == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*r.Confidence, 'f', 2, 64)
+}
+
+// parseConfidence sets the classification confidence from the cell of a result file.
+func parseConfidence(r *TerritoryResult, cell string) {
+	r.Confidence = nil
+	if confidence, err := strconv.ParseFloat(cell, 64); err == nil {
+		r.Confidence = &confidence
+	}
+}
+
+// resultStatusReview is the status of a territory result left to the manual review by --min-confidence. It is not a
+// status of the server: the item is processed, but its code is not trusted.
+const resultStatusReview = "review"
+
+// checkConfidence leaves out the code of the customs territory whose confidence is below the --min-confidence flag, so
+// the item goes to the manual review instead of being trusted. The code is kept in the warning of the item, the details
+// of the code are cleared and only the confidence is kept.
+func checkConfidence(result *ItemResult, territory string, territoryResult *TerritoryResult) {
+	if minConfidence <= 0 || territoryResult.Confidence == nil || *territoryResult.Confidence >= minConfidence {
+		return
+	}
+
+	warning := trOutputf("code %s for %s has the confidence %s below the minimum %s, review it manually",
+		territoryResult.Code, strings.ToUpper(territory), formatConfidence(territoryResult),
+		strconv.FormatFloat(minConfidence, 'f', -1, 64))
+	*territoryResult = TerritoryResult{
+		Status:        resultStatusReview,
+		Confidence:    territoryResult.Confidence,
+		LowConfidence: true,
+	}
+	if !slices.Contains(result.Warnings, warning) {
+		result.Warnings = append(result.Warnings, warning)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config is the content of the configuration file. Every value that is not set in the file keeps its default.
+type Config struct {
+	Retry   RetryConfig    `json:"retry"`
+	Outputs []OutputConfig `json:"outputs"` // written in addition to the outputs from the flags
+	TLS     TLSConfig      `json:"tls"`
+
+	ColumnAliases map[string]string        `json:"columnAliases"` // canonical column headings by the input heading
+	Profiles      map[string]ProfileConfig `json:"profiles"`      // selected with the --profile flag
+
+	TariffLinks map[string]string `json:"tariffLinks"` // tariff browser pages of the commodity codes by the customs territory
+
+	Plugins PluginsConfig `json:"plugins"` // input and output formats of the external commands
+}
+
+// ProfileConfig holds the settings of a customer's file layout, used in addition to the settings of the whole file.
+type ProfileConfig struct {
+	ColumnAliases map[string]string `json:"columnAliases"` // canonical column headings by the input heading
+}
+
+// TLSConfig configures the TLS connections to the server. The flags take precedence over the values in the file.
+type TLSConfig struct {
+	CACert string `json:"caCert"` // path of the PEM file with the additionally trusted certificates
+	Cert   string `json:"cert"`   // path of the PEM file with the client certificate
+	Key    string `json:"key"`    // path of the PEM file with the private key of the client certificate
+}
+
+// RetryConfig is the policy for retrying the failed server requests.
+type RetryConfig struct {
+	StatusCodes []int         `json:"statusCodes"` // response status codes that are retried
+	MaxAttempts int           `json:"maxAttempts"` // attempts per request, including the first one
+	Backoff     BackoffConfig `json:"backoff"`
+	Budget      int           `json:"budget"` // retries allowed in a single run, across all requests
+
+	RateLimitRetries int `json:"rateLimitRetries"` // retries of a single rate limited request, they don't use the budget
+}
+
+// BackoffConfig describes the exponential backoff curve between the retries.
+type BackoffConfig struct {
+	Initial    durationValue `json:"initial"`
+	Max        durationValue `json:"max"`
+	Multiplier float64       `json:"multiplier"`
+	Jitter     float64       `json:"jitter"` // fraction of the wait that is randomly cut off
+}
+
+func defaultConfig() Config {
+	return Config{
+		Retry: RetryConfig{
+			StatusCodes: []int{
+				http.StatusInternalServerError,
+				http.StatusBadGateway,
+				http.StatusServiceUnavailable,
+				http.StatusGatewayTimeout,
+			},
+			// The failed requests are not retried unless the configuration allows it.
+			MaxAttempts: 1,
+			Backoff: BackoffConfig{
+				Initial:    durationValue(time.Second),
+				Max:        durationValue(30 * time.Second),
+				Multiplier: 2,
+				Jitter:     0.5,
+			},
+			Budget:           20,
+			RateLimitRetries: 10,
+		},
+	}
+}
+
+// defaultConfigPath returns the path of the configuration file used when the --config flag is not set.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "customs", "config.json")
+}
+
+// loadConfig reads the configuration file. If the path is empty, the file at the default path is read if it exists.
+func loadConfig(path string) (Config, error) {
+	config := defaultConfig()
+	optional := path == ""
+	if optional {
+		path = defaultConfigPath()
+	}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if optional && errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return config, fmt.Errorf("invalid config file %q: %w", path, err)
+	}
+
+	err = config.validate()
+	if err != nil {
+		return config, fmt.Errorf("invalid config file %q: %w", path, err)
+	}
+
+	return config, nil
+}
+
+func (c Config) validate() error {
+	if err := c.Plugins.validate(); err != nil {
+		return fmt.Errorf("plugins.%w", err)
+	}
+	for i, output := range c.Outputs {
+		// The writers of the plugins are registered once the configuration is valid.
+		if _, plugin := c.Plugins.Outputs[output.Format]; plugin {
+			continue
+		}
+		if err := output.validate(); err != nil {
+			return fmt.Errorf("outputs[%d]: %w", i, err)
+		}
+	}
+	if err := validateColumnAliases(c.ColumnAliases); err != nil {
+		return fmt.Errorf("columnAliases: %w", err)
+	}
+	for name, profile := range c.Profiles {
+		if err := validateColumnAliases(profile.ColumnAliases); err != nil {
+			return fmt.Errorf("profiles.%s.columnAliases: %w", name, err)
+		}
+	}
+	if c.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("retry.maxAttempts must be at least 1")
+	}
+	if c.Retry.Budget < 0 {
+		return fmt.Errorf("retry.budget must not be negative")
+	}
+	if c.Retry.RateLimitRetries < 0 {
+		return fmt.Errorf("retry.rateLimitRetries must not be negative")
+	}
+	if c.Retry.Backoff.Multiplier < 1 {
+		return fmt.Errorf("retry.backoff.multiplier must be at least 1")
+	}
+	if c.Retry.Backoff.Jitter < 0 || c.Retry.Backoff.Jitter > 1 {
+		return fmt.Errorf("retry.backoff.jitter must be between 0 and 1")
+	}
+	if c.Retry.Backoff.Initial < 0 || c.Retry.Backoff.Max < c.Retry.Backoff.Initial {
+		return fmt.Errorf("retry.backoff.max must not be shorter than retry.backoff.initial")
+	}
+
+	return nil
+}
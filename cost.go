@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// costEstimate is the expected cost of an import, shown before the import is sent so an expensive run is noticed.
+type costEstimate struct {
+	items          int
+	pricePerItem   *float64 // from --price-per-item or the account, nil if unknown
+	currency       string
+	remainingItems *int // items left in the quota of the account, nil if unknown
+}
+
+// countChargedItems returns the number of the items the imports are charged for: every action of an item in every
+// import, e.g. an item split into the EU and NO imports with --extra-actions describe is charged three times.
+func countChargedItems(imports []ImportRequest) int {
+	items := 0
+	for _, imp := range imports {
+		for _, item := range imp.ImportItems {
+			items += len(item.Actions)
+		}
+	}
+
+	return items
+}
+
+// estimateCost returns the cost of importing the items. The price and the quota are taken from the account, the
+// --price-per-item flag takes precedence over the price of the account. An account that can't be fetched leaves the
+// estimate without them.
+func estimateCost(ctx context.Context, items int) costEstimate {
+	estimate := costEstimate{items: items}
+	account, err := getAccount(ctx, url, apiKey)
+	if err != nil {
+		slog.Debug("the account can't be fetched for the cost estimate", "error", err)
+	} else {
+		estimate.pricePerItem = account.PricePerItem
+		estimate.currency = account.Currency
+		estimate.remainingItems = account.RemainingItems
+	}
+	if pricePerItem > 0 {
+		estimate.pricePerItem = &pricePerItem
+	}
+
+	return estimate
+}
+
+// known reports whether the estimate has the price or the quota.
+func (e costEstimate) known() bool {
+	return e.pricePerItem != nil || e.remainingItems != nil
+}
+
+// exceedsQuota reports whether the import has more items than the quota of the account has left.
+func (e costEstimate) exceedsQuota() bool {
+	return e.remainingItems != nil && e.items > *e.remainingItems
+}
+
+// String returns the estimate, e.g. "1200 items × 0.05 EUR = 60.00 EUR, 5000 items left in the quota".
+func (e costEstimate) String() string {
+	var parts []string
+	if e.pricePerItem != nil {
+		price := strings.TrimSpace(strconv.FormatFloat(*e.pricePerItem, 'f', -1, 64) + " " + e.currency)
+		total := strings.TrimSpace(fmt.Sprintf("%.2f %s", float64(e.items)**e.pricePerItem, e.currency))
+		cost := trf("%d items × %s = %s", e.items, price, total)
+		parts = append(parts, cost)
+	}
+	if e.remainingItems != nil {
+		parts = append(parts, trf("%d items left in the quota", *e.remainingItems))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCountChargedItems(t *testing.T) {
+	both := []string{customsTerritoryEU, customsTerritoryNO}
+	imp := ImportRequest{ImportItems: []ImportItemRequest{
+		{ID: "1", Actions: []ActionRequest{
+			{Name: actionDetermineCommodityCodes, Parameters: Parameters{CustomsTerritories: both}},
+			{Name: actionEnrichDescription, Parameters: Parameters{CustomsTerritories: both}},
+		}},
+		{ID: "2", Actions: []ActionRequest{
+			{Name: actionDetermineCommodityCodes, Parameters: Parameters{CustomsTerritories: both}},
+		}},
+	}}
+	tests := []struct {
+		name    string
+		imports []ImportRequest
+		want    int
+	}{
+		{"no imports", nil, 0},
+		{"one import", []ImportRequest{imp}, 3},
+		{"split by territory", splitImportByTerritories(imp), 5},
+		{"compared models", append([]ImportRequest{imp}, comparedImports([]ImportRequest{imp}, "m2")...), 5},
+	}
+	for _, tt := range tests {
+		if got := countChargedItems(tt.imports); got != tt.want {
+			t.Errorf("countChargedItems() of %s = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"strings"
+)
+
+// country is a country of ISO 3166-1 with its English, German and Norwegian names and their common variants.
+type country struct {
+	alpha2 string
+	alpha3 string
+	names  []string
+}
+
+// countries are the countries of ISO 3166-1 by the alpha-2 code.
+var countries = []country{
+	{"AD", "AND", []string{"Andorra"}},
+	{"AE", "ARE", []string{"United Arab Emirates", "Vereinigte Arabische Emirate", "De forente arabiske emirater", "UAE", "VAE", "Emirates"}},
+	{"AF", "AFG", []string{"Afghanistan"}},
+	{"AG", "ATG", []string{"Antigua and Barbuda", "Antigua und Barbuda", "Antigua og Barbuda"}},
+	{"AI", "AIA", []string{"Anguilla"}},
+	{"AL", "ALB", []string{"Albania", "Albanien"}},
+	{"AM", "ARM", []string{"Armenia", "Armenien"}},
+	{"AO", "AGO", []string{"Angola"}},
+	{"AQ", "ATA", []string{"Antarctica", "Antarktis", "Antarktika"}},
+	{"AR", "ARG", []string{"Argentina", "Argentinien"}},
+	{"AS", "ASM", []string{"American Samoa", "Amerikanisch-Samoa", "Amerikansk Samoa"}},
+	{"AT", "AUT", []string{"Austria", "Österreich", "Østerrike"}},
+	{"AU", "AUS", []string{"Australia", "Australien"}},
+	{"AW", "ABW", []string{"Aruba"}},
+	{"AX", "ALA", []string{"Åland Islands", "Åland-Inseln", "Åland"}},
+	{"AZ", "AZE", []string{"Azerbaijan", "Aserbaidschan", "Aserbajdsjan"}},
+	{"BA", "BIH", []string{"Bosnia and Herzegovina", "Bosnien und Herzegowina", "Bosnia-Hercegovina", "Bosnia", "Bosnien"}},
+	{"BB", "BRB", []string{"Barbados"}},
+	{"BD", "BGD", []string{"Bangladesh", "Bangladesch"}},
+	{"BE", "BEL", []string{"Belgium", "Belgien", "Belgia"}},
+	{"BF", "BFA", []string{"Burkina Faso"}},
+	{"BG", "BGR", []string{"Bulgaria", "Bulgarien"}},
+	{"BH", "BHR", []string{"Bahrain"}},
+	{"BI", "BDI", []string{"Burundi"}},
+	{"BJ", "BEN", []string{"Benin"}},
+	{"BL", "BLM", []string{"Saint Barthélemy"}},
+	{"BM", "BMU", []string{"Bermuda"}},
+	{"BN", "BRN", []string{"Brunei Darussalam"}},
+	{"BO", "BOL", []string{"Bolivia, Plurinational State of", "Bolivia", "Bolivien, Plurinationaler Staat", "Bolivia, den flernasjonale stat", "Bolivien"}},
+	{"BQ", "BES", []string{"Bonaire, Sint Eustatius and Saba", "Bonaire, Sint Eustatius und Saba", "Bonaire, Sint Eustatius og Saba"}},
+	{"BR", "BRA", []string{"Brazil", "Brasilien", "Brasil"}},
+	{"BS", "BHS", []string{"Bahamas"}},
+	{"BT", "BTN", []string{"Bhutan"}},
+	{"BV", "BVT", []string{"Bouvet Island", "Bouvet-Insel", "Bouvetøya"}},
+	{"BW", "BWA", []string{"Botswana", "Botsuana"}},
+	{"BY", "BLR", []string{"Belarus", "Hviterussland"}},
+	{"BZ", "BLZ", []string{"Belize"}},
+	{"CA", "CAN", []string{"Canada", "Kanada"}},
+	{"CC", "CCK", []string{"Cocos (Keeling) Islands", "Kokos-(Keeling-)Inseln", "Kokosøyene"}},
+	{"CD", "COD", []string{"Congo, The Democratic Republic of the", "Demokratische Republik Kongo", "Kongo, Den demokratiske republikk", "DR Congo", "Kongo, Demokratische Republik"}},
+	{"CF", "CAF", []string{"Central African Republic", "Zentralafrikanische Republik", "Den sentralafrikanske republikk"}},
+	{"CG", "COG", []string{"Congo", "Kongo"}},
+	{"CH", "CHE", []string{"Switzerland", "Schweiz", "Sveits"}},
+	{"CI", "CIV", []string{"Côte d'Ivoire", "Elfenbenskysten", "Ivory Coast", "Elfenbeinküste"}},
+	{"CK", "COK", []string{"Cook Islands", "Cookinseln", "Cookøyene"}},
+	{"CL", "CHL", []string{"Chile"}},
+	{"CM", "CMR", []string{"Cameroon", "Kamerun"}},
+	{"CN", "CHN", []string{"China", "Kina", "PRC", "P.R. China", "VR China"}},
+	{"CO", "COL", []string{"Colombia", "Kolumbien"}},
+	{"CR", "CRI", []string{"Costa Rica"}},
+	{"CU", "CUB", []string{"Cuba", "Kuba"}},
+	{"CV", "CPV", []string{"Cabo Verde", "Kap Verde", "Kapp Verde", "Cape Verde"}},
+	{"CW", "CUW", []string{"Curaçao"}},
+	{"CX", "CXR", []string{"Christmas Island", "Weihnachtsinseln", "Christmasøya"}},
+	{"CY", "CYP", []string{"Cyprus", "Zypern", "Kypros"}},
+	{"CZ", "CZE", []string{"Czechia", "Tschechien", "Tsjekkia", "Czech Republic", "Tschechische Republik"}},
+	{"DE", "DEU", []string{"Germany", "Deutschland", "Tyskland"}},
+	{"DJ", "DJI", []string{"Djibouti", "Dschibuti"}},
+	{"DK", "DNK", []string{"Denmark", "Dänemark", "Danmark"}},
+	{"DM", "DMA", []string{"Dominica"}},
+	{"DO", "DOM", []string{"Dominican Republic", "Dominikanische Republik", "Den dominikanske republikk"}},
+	{"DZ", "DZA", []string{"Algeria", "Algerien", "Algerie"}},
+	{"EC", "ECU", []string{"Ecuador"}},
+	{"EE", "EST", []string{"Estonia", "Estland"}},
+	{"EG", "EGY", []string{"Egypt", "Ägypten"}},
+	{"EH", "ESH", []string{"Western Sahara", "Westsahara", "Vest-Sahara"}},
+	{"ER", "ERI", []string{"Eritrea"}},
+	{"ES", "ESP", []string{"Spain", "Spanien", "Spania"}},
+	{"ET", "ETH", []string{"Ethiopia", "Äthiopien", "Etiopia"}},
+	{"FI", "FIN", []string{"Finland", "Finnland"}},
+	{"FJ", "FJI", []string{"Fiji", "Fidschi"}},
+	{"FK", "FLK", []string{"Falkland Islands (Malvinas)", "Falklandinseln (Malwinen)", "Falklandsøyene"}},
+	{"FM", "FSM", []string{"Micronesia, Federated States of", "Mikronesien, Föderierte Staaten von", "Mikronesia, Føderasjonen", "Micronesia", "Mikronesien"}},
+	{"FO", "FRO", []string{"Faroe Islands", "Färöer-Inseln", "Færøyene"}},
+	{"FR", "FRA", []string{"France", "Frankreich", "Frankrike"}},
+	{"GA", "GAB", []string{"Gabon", "Gabun"}},
+	{"GB", "GBR", []string{"United Kingdom", "Vereinigtes Königreich", "Storbritannia", "UK", "Great Britain", "Britain", "England", "Scotland", "Wales", "Großbritannien"}},
+	{"GD", "GRD", []string{"Grenada"}},
+	{"GE", "GEO", []string{"Georgia", "Georgien"}},
+	{"GF", "GUF", []string{"French Guiana", "Französisch-Guyana", "Fransk Guyana"}},
+	{"GG", "GGY", []string{"Guernsey"}},
+	{"GH", "GHA", []string{"Ghana"}},
+	{"GI", "GIB", []string{"Gibraltar"}},
+	{"GL", "GRL", []string{"Greenland", "Grönland", "Grønland"}},
+	{"GM", "GMB", []string{"Gambia"}},
+	{"GN", "GIN", []string{"Guinea"}},
+	{"GP", "GLP", []string{"Guadeloupe"}},
+	{"GQ", "GNQ", []string{"Equatorial Guinea", "Äquatorialguinea", "Ekvatorial-Guinea"}},
+	{"GR", "GRC", []string{"Greece", "Griechenland", "Hellas"}},
+	{"GS", "SGS", []string{"South Georgia and the South Sandwich Islands", "South Georgia und die Südlichen Sandwichinseln", "Sør-Georgia og Sør-Sandwichøyene"}},
+	{"GT", "GTM", []string{"Guatemala"}},
+	{"GU", "GUM", []string{"Guam"}},
+	{"GW", "GNB", []string{"Guinea-Bissau"}},
+	{"GY", "GUY", []string{"Guyana"}},
+	{"HK", "HKG", []string{"Hong Kong"}},
+	{"HM", "HMD", []string{"Heard Island and McDonald Islands", "Heard und McDonaldinseln", "Heard- og McDonaldøyene"}},
+	{"HN", "HND", []string{"Honduras"}},
+	{"HR", "HRV", []string{"Croatia", "Kroatien", "Kroatia"}},
+	{"HT", "HTI", []string{"Haiti"}},
+	{"HU", "HUN", []string{"Hungary", "Ungarn"}},
+	{"ID", "IDN", []string{"Indonesia", "Indonesien"}},
+	{"IE", "IRL", []string{"Ireland", "Irland"}},
+	{"IL", "ISR", []string{"Israel"}},
+	{"IM", "IMN", []string{"Isle of Man", "Insel Man", "Man"}},
+	{"IN", "IND", []string{"India", "Indien"}},
+	{"IO", "IOT", []string{"British Indian Ocean Territory", "Britisches Territorium im Indischen Ozean", "Det britiske territoriet i Indiahavet"}},
+	{"IQ", "IRQ", []string{"Iraq", "Irak"}},
+	{"IR", "IRN", []string{"Iran, Islamic Republic of", "Iran", "Iran, Islamische Republik", "Iran, Den islamske republikk"}},
+	{"IS", "ISL", []string{"Iceland", "Island"}},
+	{"IT", "ITA", []string{"Italy", "Italien", "Italia"}},
+	{"JE", "JEY", []string{"Jersey"}},
+	{"JM", "JAM", []string{"Jamaica", "Jamaika"}},
+	{"JO", "JOR", []string{"Jordan", "Jordanien"}},
+	{"JP", "JPN", []string{"Japan"}},
+	{"KE", "KEN", []string{"Kenya", "Kenia"}},
+	{"KG", "KGZ", []string{"Kyrgyzstan", "Kirgisistan"}},
+	{"KH", "KHM", []string{"Cambodia", "Kambodscha", "Kambodsja"}},
+	{"KI", "KIR", []string{"Kiribati"}},
+	{"KM", "COM", []string{"Comoros", "Komoren", "Komorene"}},
+	{"KN", "KNA", []string{"Saint Kitts and Nevis", "St. Kitts und Nevis", "Saint Kitts og Nevis"}},
+	{"KP", "PRK", []string{"Korea, Democratic People's Republic of", "North Korea", "Korea, Demokratische Volksrepublik", "Korea, Den demokratiske folkerepublikk", "Nordkorea"}},
+	{"KR", "KOR", []string{"Korea, Republic of", "South Korea", "Korea, Republik", "Korea, Republikken", "Korea", "Südkorea", "Sør-Korea"}},
+	{"KW", "KWT", []string{"Kuwait"}},
+	{"KY", "CYM", []string{"Cayman Islands", "Cayman-Inseln", "Caymanøyene"}},
+	{"KZ", "KAZ", []string{"Kazakhstan", "Kasachstan", "Kasakhstan"}},
+	{"LA", "LAO", []string{"Lao People's Democratic Republic", "Laos", "Laos, Demokratische Volksrepublik", "Den demokratiske folkerepublikk Laos"}},
+	{"LB", "LBN", []string{"Lebanon", "Libanon"}},
+	{"LC", "LCA", []string{"Saint Lucia", "St. Lucia"}},
+	{"LI", "LIE", []string{"Liechtenstein"}},
+	{"LK", "LKA", []string{"Sri Lanka"}},
+	{"LR", "LBR", []string{"Liberia"}},
+	{"LS", "LSO", []string{"Lesotho"}},
+	{"LT", "LTU", []string{"Lithuania", "Litauen"}},
+	{"LU", "LUX", []string{"Luxembourg", "Luxemburg"}},
+	{"LV", "LVA", []string{"Latvia", "Lettland"}},
+	{"LY", "LBY", []string{"Libya", "Libyen"}},
+	{"MA", "MAR", []string{"Morocco", "Marokko"}},
+	{"MC", "MCO", []string{"Monaco"}},
+	{"MD", "MDA", []string{"Moldova, Republic of", "Moldova", "Moldau, Republik", "Moldova, Republikken", "Moldau"}},
+	{"ME", "MNE", []string{"Montenegro"}},
+	{"MF", "MAF", []string{"Saint Martin (French part)", "Saint Martin (Französischer Teil)", "Saint Martin (fransk del)"}},
+	{"MG", "MDG", []string{"Madagascar", "Madagaskar"}},
+	{"MH", "MHL", []string{"Marshall Islands", "Marshallinseln", "Marshalløyene"}},
+	{"MK", "MKD", []string{"North Macedonia", "Nordmazedonien", "Nord-Makedonia", "Macedonia", "Mazedonien", "Makedonia"}},
+	{"ML", "MLI", []string{"Mali"}},
+	{"MM", "MMR", []string{"Myanmar", "Burma", "Birma"}},
+	{"MN", "MNG", []string{"Mongolia", "Mongolei"}},
+	{"MO", "MAC", []string{"Macao"}},
+	{"MP", "MNP", []string{"Northern Mariana Islands", "Nördliche Marianen", "Nord-Marianene"}},
+	{"MQ", "MTQ", []string{"Martinique"}},
+	{"MR", "MRT", []string{"Mauritania", "Mauretanien"}},
+	{"MS", "MSR", []string{"Montserrat"}},
+	{"MT", "MLT", []string{"Malta"}},
+	{"MU", "MUS", []string{"Mauritius"}},
+	{"MV", "MDV", []string{"Maldives", "Malediven", "Maldivene"}},
+	{"MW", "MWI", []string{"Malawi"}},
+	{"MX", "MEX", []string{"Mexico", "Mexiko"}},
+	{"MY", "MYS", []string{"Malaysia"}},
+	{"MZ", "MOZ", []string{"Mozambique", "Mosambik"}},
+	{"NA", "NAM", []string{"Namibia"}},
+	{"NC", "NCL", []string{"New Caledonia", "Neukaledonien", "Ny-Caledonia"}},
+	{"NE", "NER", []string{"Niger"}},
+	{"NF", "NFK", []string{"Norfolk Island", "Norfolkinsel", "Norfolkøya"}},
+	{"NG", "NGA", []string{"Nigeria"}},
+	{"NI", "NIC", []string{"Nicaragua"}},
+	{"NL", "NLD", []string{"Netherlands", "Niederlande", "Nederland", "Holland"}},
+	{"NO", "NOR", []string{"Norway", "Norwegen", "Norge"}},
+	{"NP", "NPL", []string{"Nepal"}},
+	{"NR", "NRU", []string{"Nauru"}},
+	{"NU", "NIU", []string{"Niue"}},
+	{"NZ", "NZL", []string{"New Zealand", "Neuseeland"}},
+	{"OM", "OMN", []string{"Oman"}},
+	{"PA", "PAN", []string{"Panama"}},
+	{"PE", "PER", []string{"Peru"}},
+	{"PF", "PYF", []string{"French Polynesia", "Französisch-Polynesien", "Fransk Polynesia"}},
+	{"PG", "PNG", []string{"Papua New Guinea", "Papua-Neuguinea", "Papua Ny-Guinea"}},
+	{"PH", "PHL", []string{"Philippines", "Philippinen", "Filippinene"}},
+	{"PK", "PAK", []string{"Pakistan"}},
+	{"PL", "POL", []string{"Poland", "Polen"}},
+	{"PM", "SPM", []string{"Saint Pierre and Miquelon", "St. Pierre und Miquelon", "Saint-Pierre og Miquelon"}},
+	{"PN", "PCN", []string{"Pitcairn"}},
+	{"PR", "PRI", []string{"Puerto Rico"}},
+	{"PS", "PSE", []string{"Palestine, State of", "Palästina, Staat", "Palestina, staten", "Palestine", "Palästina"}},
+	{"PT", "PRT", []string{"Portugal"}},
+	{"PW", "PLW", []string{"Palau"}},
+	{"PY", "PRY", []string{"Paraguay"}},
+	{"QA", "QAT", []string{"Qatar", "Katar"}},
+	{"RE", "REU", []string{"Réunion"}},
+	{"RO", "ROU", []string{"Romania", "Rumänien"}},
+	{"RS", "SRB", []string{"Serbia", "Serbien"}},
+	{"RU", "RUS", []string{"Russian Federation", "Russische Föderation", "Den russiske føderasjon", "Russia", "Russland"}},
+	{"RW", "RWA", []string{"Rwanda", "Ruanda"}},
+	{"SA", "SAU", []string{"Saudi Arabia", "Saudi-Arabien"}},
+	{"SB", "SLB", []string{"Solomon Islands", "Salomoninseln", "Salomonøyene"}},
+	{"SC", "SYC", []string{"Seychelles", "Seychellen", "Seychellene"}},
+	{"SD", "SDN", []string{"Sudan"}},
+	{"SE", "SWE", []string{"Sweden", "Schweden", "Sverige"}},
+	{"SG", "SGP", []string{"Singapore", "Singapur"}},
+	{"SH", "SHN", []string{"Saint Helena, Ascension and Tristan da Cunha", "St. Helena, Ascension und Tristan da Cunha", "Saint Helena, Ascension og Tristan da Cunha"}},
+	{"SI", "SVN", []string{"Slovenia", "Slowenien"}},
+	{"SJ", "SJM", []string{"Svalbard and Jan Mayen", "Svalbard und Jan Mayen", "Svalbard og Jan Mayen"}},
+	{"SK", "SVK", []string{"Slovakia", "Slowakei"}},
+	{"SL", "SLE", []string{"Sierra Leone"}},
+	{"SM", "SMR", []string{"San Marino"}},
+	{"SN", "SEN", []string{"Senegal"}},
+	{"SO", "SOM", []string{"Somalia"}},
+	{"SR", "SUR", []string{"Suriname", "Surinam"}},
+	{"SS", "SSD", []string{"South Sudan", "Südsudan", "Sør-Sudan"}},
+	{"ST", "STP", []string{"Sao Tome and Principe", "São Tomé und Príncipe", "São Tomé og Príncipe"}},
+	{"SV", "SLV", []string{"El Salvador"}},
+	{"SX", "SXM", []string{"Sint Maarten (Dutch part)", "Saint-Martin (Niederländischer Teil)", "Sint Maarten (nederlandsk del)"}},
+	{"SY", "SYR", []string{"Syrian Arab Republic", "Syria", "Syrien, Arabische Republik", "Den arabiske republikk Syria", "Syrien"}},
+	{"SZ", "SWZ", []string{"Eswatini", "Eswatini (tidligere Swasiland)", "Swaziland", "Swasiland"}},
+	{"TC", "TCA", []string{"Turks and Caicos Islands", "Turks- und Caicosinseln", "Turks- og Caicosøyene"}},
+	{"TD", "TCD", []string{"Chad", "Tschad", "Tsjad"}},
+	{"TF", "ATF", []string{"French Southern Territories", "Französische Süd- und Antarktisgebiete", "Franske sørlige territorier"}},
+	{"TG", "TGO", []string{"Togo"}},
+	{"TH", "THA", []string{"Thailand"}},
+	{"TJ", "TJK", []string{"Tajikistan", "Tadschikistan", "Tadsjikistan"}},
+	{"TK", "TKL", []string{"Tokelau"}},
+	{"TL", "TLS", []string{"Timor-Leste", "Øst-Timor", "East Timor", "Osttimor"}},
+	{"TM", "TKM", []string{"Turkmenistan"}},
+	{"TN", "TUN", []string{"Tunisia", "Tunesien"}},
+	{"TO", "TON", []string{"Tonga"}},
+	{"TR", "TUR", []string{"Türkiye", "Türkei", "Turkey", "Tyrkia"}},
+	{"TT", "TTO", []string{"Trinidad and Tobago", "Trinidad und Tobago", "Trinidad og Tobago"}},
+	{"TV", "TUV", []string{"Tuvalu"}},
+	{"TW", "TWN", []string{"Taiwan, Province of China", "Taiwan", "Taiwan, Chinesische Provinz", "Taiwan, Den kinesiske provins"}},
+	{"TZ", "TZA", []string{"Tanzania, United Republic of", "Tanzania", "Tansania, Vereinigte Republik", "Tanzania, Forbundsrepublikken", "Tansania"}},
+	{"UA", "UKR", []string{"Ukraine", "Ukraina"}},
+	{"UG", "UGA", []string{"Uganda"}},
+	{"UM", "UMI", []string{"United States Minor Outlying Islands", "Mindre utenforliggende øyer til USA"}},
+	{"US", "USA", []string{"United States", "Vereinigte Staaten", "De forente stater", "USA", "America", "Amerika", "Vereinigte Staaten von Amerika"}},
+	{"UY", "URY", []string{"Uruguay"}},
+	{"UZ", "UZB", []string{"Uzbekistan", "Usbekistan"}},
+	{"VA", "VAT", []string{"Holy See (Vatican City State)", "Heiliger Stuhl (Staat Vatikanstadt)", "Vatikanstaten", "Vatican", "Vatikanstadt"}},
+	{"VC", "VCT", []string{"Saint Vincent and the Grenadines", "St. Vincent und die Grenadinen", "Saint Vincent og Grenadinene"}},
+	{"VE", "VEN", []string{"Venezuela, Bolivarian Republic of", "Venezuela", "Venezuela, Bolivarische Republik", "Venezuela, Republikken"}},
+	{"VG", "VGB", []string{"Virgin Islands, British", "Britische Jungferninseln", "Jomfruøyene (Storbritannia)"}},
+	{"VI", "VIR", []string{"Virgin Islands, U.S.", "Amerikanische Jungferninseln", "Jomfruøyene (USA)"}},
+	{"VN", "VNM", []string{"Viet Nam"}},
+	{"VU", "VUT", []string{"Vanuatu"}},
+	{"WF", "WLF", []string{"Wallis and Futuna", "Wallis und Futuna", "Wallis og Futunaøyene"}},
+	{"WS", "WSM", []string{"Samoa"}},
+	{"YE", "YEM", []string{"Yemen", "Jemen"}},
+	{"YT", "MYT", []string{"Mayotte"}},
+	{"ZA", "ZAF", []string{"South Africa", "Südafrika", "Sør-Afrika"}},
+	{"ZM", "ZMB", []string{"Zambia", "Sambia"}},
+	{"ZW", "ZWE", []string{"Zimbabwe", "Simbabwe"}},
+}
+
+// countryCodes are the alpha-2 codes of the countries by their normalized codes and names.
+var countryCodes = func() map[string]string {
+	codes := map[string]string{}
+	// The codes take precedence over the names, e.g. "NO" is Norway.
+	for _, c := range countries {
+		codes[normalizeName(c.alpha2)] = c.alpha2
+		codes[normalizeName(c.alpha3)] = c.alpha2
+	}
+	for _, c := range countries {
+		for _, name := range c.names {
+			if _, ok := codes[normalizeName(name)]; !ok {
+				codes[normalizeName(name)] = c.alpha2
+			}
+		}
+	}
+
+	return codes
+}()
+
+// countryCode returns the ISO 3166-1 alpha-2 code of the country given by its code or name, e.g. "DE" for "Germany",
+// "DEU" or "Deutschland". It reports whether the country is known.
+func countryCode(value string) (string, bool) {
+	code, ok := countryCodes[normalizeName(value)]
+
+	return code, ok
+}
+
+// normalizeCountry returns the country of origin as the alpha-2 code. The unknown values are returned as they are,
+// only trimmed.
+func normalizeCountry(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if code, ok := countryCode(trimmed); ok {
+		return &code
+	}
+
+	return &trimmed
+}
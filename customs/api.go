@@ -0,0 +1,326 @@
+package customs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	ImportItemStatusPending    = "pending"
+	ImportItemStatusProcessing = "processing"
+	ImportItemStatusProcessed  = "processed"
+	ImportItemStatusFailed     = "failed"
+)
+
+const (
+	ActionDetermineCommodityCodes = "determineCommodityCodes"
+	ActionEnrichDescription       = "enrichDescription" // description of the item improved for the customs declaration
+	ActionDetermineOrigin         = "determineOrigin"   // country of origin of the item
+)
+
+// The details of the commodity codes the server returns with the codes on request, see Parameters.Include.
+const (
+	IncludeRates    = "rates"    // duty and VAT rates
+	IncludeMeasures = "measures" // trade measures, e.g. the anti-dumping duties and the licence requirements
+
+	IncludeExplanation = "explanation" // explanation of the classification and the matched nomenclature path
+)
+
+// The versions of the API.
+const (
+	APIVersion1 = "v1"
+)
+
+// SupportedAPIVersions are the API versions the client supports, the newest last.
+var SupportedAPIVersions = []string{APIVersion1}
+
+// The types of the trade measures of a commodity code.
+const (
+	MeasureTypeAntiDumping = "antiDumping"
+	MeasureTypeQuota       = "quota"
+	MeasureTypeLicence     = "licence"
+	MeasureTypeProhibition = "prohibition"
+)
+
+var (
+	ErrFailed          = fmt.Errorf("failed")
+	ErrNotProcessed    = fmt.Errorf("not processed")
+	ErrRequestTooLarge = fmt.Errorf("request too large")
+	ErrNotSupported    = fmt.Errorf("not supported by the server")
+	ErrNotFound        = fmt.Errorf("not found")
+	ErrUnauthorized    = fmt.Errorf("API key is not valid or not authorized")
+	// ErrInvalidResponse is returned when a response doesn't have the shape the client expects, e.g. after an
+	// incompatible change of the API.
+	ErrInvalidResponse = fmt.Errorf("invalid server response")
+)
+
+// StatusError is returned for a response with a status code the request doesn't expect.
+type StatusError struct {
+	Operation  string // e.g. "importing items"
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code while %s %d\n%s\n", e.Operation, e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the request failed with a status code of a failure that is likely to go away, e.g. a rate
+// limit or a server error, so the request can be sent again later.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+type ImportRequest struct {
+	ImportItems []ImportItemRequest `json:"items"`
+}
+
+type ImportItemRequest struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Description     string          `json:"description"`
+	Category        *string         `json:"category,omitempty"`
+	Subcategory     *string         `json:"subcategory,omitempty"`
+	CountryOfOrigin *string         `json:"countryOfOrigin,omitempty"`
+	GrossMass       *float64        `json:"grossMass,omitempty"`
+	NetMass         *float64        `json:"netMass,omitempty"`
+	WeightUnit      *string         `json:"weightUnit,omitempty"`
+	Language        *string         `json:"language,omitempty"` // language of the name and description detected by the CLI, a hint for the server
+	Actions         []ActionRequest `json:"actions"`            // actions to perform on the item
+
+	// Attributes are the other properties of the item, e.g. {"material": "cotton", "brand": "Acme"}, that help the
+	// classification.
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Documents are the IDs of the documents of the item uploaded with Client.UploadDocument, e.g. the datasheets the
+	// classification depends on.
+	Documents []string `json:"documents,omitempty"`
+}
+
+type ActionRequest struct {
+	Name       string     `json:"name"`
+	Parameters Parameters `json:"parameters"`
+}
+
+type Parameters struct {
+	CustomsTerritories []string `json:"customsTerritories"`
+	Model              *string  `json:"model,omitempty"`   // Model is a non-documented internal property, don't use it.
+	Include            []string `json:"include,omitempty"` // details returned with the codes, e.g. IncludeRates
+}
+
+type ImportStatus struct {
+	Status string `json:"status"`
+}
+
+type ImportResponse struct {
+	ID          string               `json:"id"`
+	ImportItems []ImportItemResponse `json:"items"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+}
+
+type ImportItemResponse struct {
+	ID              string                   `json:"id"`
+	Name            string                   `json:"name"`
+	Description     string                   `json:"description"`
+	Category        *string                  `json:"category,omitempty"`
+	Subcategory     *string                  `json:"subcategory,omitempty"`
+	CountryOfOrigin *string                  `json:"countryOfOrigin,omitempty"`
+	GrossMass       *float64                 `json:"grossMass,omitempty"`
+	NetMass         *float64                 `json:"netMass,omitempty"`
+	WeightUnit      *string                  `json:"weightUnit,omitempty"`
+	Actions         []ActionResponse         `json:"actions,omitempty"`
+	Tarics          []CommodityCodesResponse `json:"commodityCodes"`
+	Warnings        []WarningResponse        `json:"warnings,omitempty"` // non-fatal findings, e.g. an ambiguous description
+	CreatedAt       time.Time                `json:"createdAt"`
+	UpdatedAt       time.Time                `json:"updatedAt"`
+}
+
+// TaricByTerritory returns the commodity code of the customs territory, or nil if the item has none.
+func (i ImportItemResponse) TaricByTerritory(territory string) *CommodityCodesResponse {
+	for _, taric := range i.Tarics {
+		if taric.CustomsTerritory == territory {
+			return &taric
+		}
+	}
+
+	return nil
+}
+
+// Action returns the action of the item by the name, or nil if the item has none.
+func (i ImportItemResponse) Action(name string) *ActionResponse {
+	for _, action := range i.Actions {
+		if action.Name == name {
+			return &action
+		}
+	}
+
+	return nil
+}
+
+// CountMissingResults returns the number of requested items that have no final result in the response: they are
+// missing, any of their requested actions is still processing, or they are processed without the codes of all
+// requested territories.
+func (r ImportResponse) CountMissingResults(request ImportRequest) int {
+	items := make(map[string]ImportItemResponse, len(r.ImportItems))
+	for _, item := range r.ImportItems {
+		items[item.ID] = item
+	}
+
+	missing := 0
+	for _, requested := range request.ImportItems {
+		item, ok := items[requested.ID]
+		if !ok || !hasFinalResults(requested, item) {
+			missing++
+		}
+	}
+
+	return missing
+}
+
+// hasFinalResults reports whether all requested actions of the item are processed or failed, and the processed
+// commodity codes action has the codes of all requested territories.
+func hasFinalResults(requested ImportItemRequest, item ImportItemResponse) bool {
+	for _, requestedAction := range requested.Actions {
+		action := item.Action(requestedAction.Name)
+		if action == nil || (action.Status != ImportItemStatusProcessed && action.Status != ImportItemStatusFailed) {
+			return false
+		}
+		if action.Name != ActionDetermineCommodityCodes || action.Status == ImportItemStatusFailed {
+			continue
+		}
+		for _, territory := range action.Parameters.CustomsTerritories {
+			if item.TaricByTerritory(territory) == nil {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+type ActionResponse struct {
+	Name        string     `json:"name"`
+	Parameters  Parameters `json:"parameters"`
+	Status      string     `json:"status"`
+	Error       *string    `json:"error,omitempty"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"maxAttempts"`
+
+	// Output is the result of the actions other than ActionDetermineCommodityCodes, e.g. {"description": "..."} of
+	// ActionEnrichDescription.
+	Output map[string]any `json:"output,omitempty"`
+}
+
+// WarningResponse is a non-fatal warning about the item. The server sends it either as a message or as an object with
+// the code and the message.
+type WarningResponse struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+func (w *WarningResponse) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		*w = WarningResponse{Message: message}
+		return nil
+	}
+
+	type warning WarningResponse
+	return json.Unmarshal(data, (*warning)(w))
+}
+
+type CommodityCodesResponse struct {
+	CustomsTerritory string `json:"customsTerritory"`
+	Code             string `json:"code"`
+	Description      string `json:"description,omitempty"` // nomenclature description of the code, if the server returns it
+	DutyRate         string `json:"dutyRate,omitempty"`    // third country duty rate, e.g. "12%", with IncludeRates
+	VATRate          string `json:"vatRate,omitempty"`     // standard VAT rate of the territory, e.g. "25%", with IncludeRates
+
+	Measures []MeasureResponse `json:"measures,omitempty"` // trade measures of the code, with IncludeMeasures
+
+	SupplementaryUnit string `json:"supplementaryUnit,omitempty"` // unit of the additional quantity the code requires, e.g. "p/st" or "l"
+
+	Confidence *float64 `json:"confidence,omitempty"` // classification confidence from 0 to 1, if the server returns it
+
+	// Explanation of the classification and the nomenclature path of the code from the section down to the code,
+	// e.g. ["Section XI", "Chapter 62", "6205", "6205 20 00"], with IncludeExplanation.
+	Explanation      string   `json:"explanation,omitempty"`
+	NomenclaturePath []string `json:"nomenclaturePath,omitempty"`
+}
+
+// MeasureResponse is a trade measure of a commodity code, e.g. an anti-dumping duty or a licence requirement.
+type MeasureResponse struct {
+	Type        string `json:"type"`                  // one of the MeasureType constants
+	Description string `json:"description,omitempty"` // e.g. the rate and the country of an anti-dumping duty
+}
+
+// AccountResponse describes the account of the API key.
+type AccountResponse struct {
+	CustomsTerritories []string `json:"customsTerritories"` // territories the API key is allowed to import for
+
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name,omitempty"`        // name of the organization of the account
+	APIKeyName  string   `json:"apiKeyName,omitempty"`  // name the API key was given when it was created
+	Permissions []string `json:"permissions,omitempty"` // what the API key is allowed to do, e.g. "imports:write"
+
+	PricePerItem   *float64 `json:"pricePerItem,omitempty"`   // price of an imported item in the Currency, if the server returns it
+	Currency       string   `json:"currency,omitempty"`       // e.g. "EUR"
+	RemainingItems *int     `json:"remainingItems,omitempty"` // items left in the quota of the billing period
+}
+
+// UsageResponse is the usage of the account in the current billing period, usually the calendar month.
+type UsageResponse struct {
+	PeriodStart    time.Time           `json:"periodStart"`
+	PeriodEnd      time.Time           `json:"periodEnd"`
+	ItemsUsed      int                 `json:"itemsUsed"`                // items imported in the period
+	ItemsQuota     *int                `json:"itemsQuota,omitempty"`     // nil for the accounts without a quota
+	RemainingItems *int                `json:"remainingItems,omitempty"` // items left in the quota of the period
+	RateLimits     []RateLimitResponse `json:"rateLimits,omitempty"`
+}
+
+// RateLimitResponse is a limit of the requests of the API key, e.g. 60 requests per minute.
+type RateLimitResponse struct {
+	Limit     int    `json:"limit"`               // requests allowed in the window
+	Remaining *int   `json:"remaining,omitempty"` // requests left in the current window
+	Window    string `json:"window"`              // e.g. "1m" or "1h"
+}
+
+// CategoriesResponse lists the categories of the items the server accepts.
+type CategoriesResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+}
+
+// CategoryResponse is a category with the subcategories the server accepts for it.
+type CategoryResponse struct {
+	Name          string   `json:"name"`
+	Subcategories []string `json:"subcategories,omitempty"`
+}
+
+// DocumentResponse is a document uploaded for the items, e.g. a datasheet or a specification.
+type DocumentResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// The statuses of the server health.
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+	HealthStatusDown     = "down"
+)
+
+// HealthResponse describes the state of the server, e.g. degraded during an incident or a maintenance.
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// VersionResponse describes the version of the server, the oldest CLI version and the API versions it supports.
+type VersionResponse struct {
+	Version           string   `json:"version"`
+	MinimumCLIVersion string   `json:"minimumCliVersion,omitempty"`
+	APIVersions       []string `json:"apiVersions,omitempty"` // only APIVersion1 if empty
+}
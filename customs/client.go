@@ -0,0 +1,529 @@
+package customs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultPollInterval = time.Second
+
+// Doer sends the HTTP requests, e.g. an *http.Client or a client that retries the failed requests.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is the client of the customs API. The zero values of the optional fields are usable defaults.
+type Client struct {
+	URL    string
+	APIKey string
+
+	HTTPClient Doer   // NewHTTPClient with the default options if nil
+	APIVersion string // version of the API the requests are sent to, e.g. "v1" (default APIVersion1)
+	RequestID  string // sent in the X-Request-ID header if set, so the requests can be found in the server logs
+	UserAgent  string
+
+	// GzipThreshold is the size of the import request body above which the body is compressed, 0 disables the
+	// compression.
+	GzipThreshold int
+	// Idempotency sends the Idempotency-Key header with the import requests if set, see IdempotencyOptions.
+	Idempotency *IdempotencyOptions
+	// PollInterval is the wait between the status requests while waiting for an import (default 1s).
+	PollInterval time.Duration
+	// OnPoll is called before every status request, e.g. to show the progress.
+	OnPoll func()
+}
+
+// IdempotencyOptions configures the Idempotency-Key header of the import requests. The key is the SHA-256 hash of the
+// uncompressed request body, so the same items sent again, e.g. by a request retried after a network failure or a file
+// imported again after a lost response, are recognized by the server as the same import and billed only once.
+type IdempotencyOptions struct {
+	// Salt is hashed together with the body if set, so the same items sent again on purpose, e.g. to be classified
+	// again, get a different key and create a new import.
+	Salt string
+}
+
+// NewClient returns the client of the API at the URL, e.g. "https://drotsolutions.com".
+func NewClient(url, apiKey string) *Client {
+	return &Client{URL: url, APIKey: apiKey}
+}
+
+// NegotiateAPIVersion selects the newest API version supported by both the client and the server, and returns it.
+// It returns ErrNotSupported if they have no version in common.
+func (c *Client) NegotiateAPIVersion(ctx context.Context) (string, error) {
+	server, err := c.GetVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	serverVersions := server.APIVersions
+	if len(serverVersions) == 0 {
+		serverVersions = []string{APIVersion1}
+	}
+
+	for i := len(SupportedAPIVersions) - 1; i >= 0; i-- {
+		if slices.Contains(serverVersions, SupportedAPIVersions[i]) {
+			c.APIVersion = SupportedAPIVersions[i]
+			return c.APIVersion, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: the server supports the API versions %s, the client supports %s", ErrNotSupported,
+		strings.Join(serverVersions, ", "), strings.Join(SupportedAPIVersions, ", "))
+}
+
+// endpoint returns the URL of the endpoint in the API version of the client, e.g. "/items/imports".
+func (c *Client) endpoint(path string) string {
+	return c.URL + c.apiPath(path)
+}
+
+// apiPath returns the path of the endpoint in the API version of the client on the server, e.g. "/api/v1/usage".
+func (c *Client) apiPath(path string) string {
+	version := c.APIVersion
+	if version == "" {
+		version = APIVersion1
+	}
+
+	return fmt.Sprintf("/api/%s%s", version, path)
+}
+
+// Import sends the import, waits for it to be processed and returns it. A failed or not processed import is returned
+// together with ErrFailed or ErrNotProcessed, so the errors of the items can be inspected.
+func (c *Client) Import(ctx context.Context, request ImportRequest, timeout time.Duration) (*ImportResponse, error) {
+	importLocation, err := c.SendImport(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	waitErr := c.WaitForProcessing(ctx, importLocation, timeout)
+	if waitErr != nil && !errors.Is(waitErr, ErrFailed) && !errors.Is(waitErr, ErrNotProcessed) {
+		return nil, waitErr
+	}
+
+	response, err := c.GetImport(ctx, importLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, waitErr
+}
+
+// SendImport sends the import and returns its location, which is passed to the other methods.
+func (c *Client) SendImport(ctx context.Context, request ImportRequest) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	// The key is the hash of the uncompressed body, so it doesn't depend on the compression.
+	var idempotencyKey string
+	if c.Idempotency != nil {
+		idempotencyKey = c.Idempotency.key(body)
+	}
+
+	compressed := c.GzipThreshold > 0 && len(body) > c.GzipThreshold
+	if compressed {
+		body, err = gzipBody(body)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint("/items/imports"), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	c.addHeaders(req)
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	res, err := c.send(req)
+	if err != nil {
+		return "", err
+	}
+
+	if http.StatusRequestEntityTooLarge == res.StatusCode {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		return "", fmt.Errorf("%w: the import request of %d items is larger than the server accepts", ErrRequestTooLarge, len(request.ImportItems))
+	}
+	if http.StatusCreated != res.StatusCode {
+		resBody, bodyErr := io.ReadAll(res.Body)
+		// Added to help with debugging. If there is an error while readying the body, ignore it because the original issue is more important.
+		if bodyErr == nil {
+			_ = res.Body.Close()
+		}
+
+		return "", &StatusError{Operation: "importing items", StatusCode: res.StatusCode, Body: string(resBody)}
+	}
+
+	return res.Header.Get("Location"), nil
+}
+
+// GetImport returns the import with the results of its items.
+func (c *Client) GetImport(ctx context.Context, importLocation string) (*ImportResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.URL, importLocation), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.addHeaders(req)
+	res, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	if http.StatusNotFound == res.StatusCode {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("%w: import %s", ErrNotFound, importLocation)
+	}
+	if http.StatusOK != res.StatusCode {
+		resBody, bodyErr := io.ReadAll(res.Body)
+		// Added to help with debugging. If there is an error while readying the body, ignore it because the original issue is more important.
+		if bodyErr == nil {
+			_ = res.Body.Close()
+		}
+
+		return nil, &StatusError{Operation: "getting an import", StatusCode: res.StatusCode, Body: string(resBody)}
+	}
+
+	var imp ImportResponse
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resBody, importResponseSchema, "the import", &imp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &imp, nil
+}
+
+// DeleteImport deletes the import with the items and their results from the server. It returns ErrNotFound if the
+// import is already deleted and ErrNotSupported if the server doesn't delete the imports.
+func (c *Client) DeleteImport(ctx context.Context, importLocation string) error {
+	return c.do(ctx, http.MethodDelete, importLocation, nil, &response{
+		operation: "deleting an import",
+		statuses:  []int{http.StatusOK, http.StatusAccepted, http.StatusNoContent},
+		errors: map[int]error{
+			http.StatusNotFound:         fmt.Errorf("%w: import %s", ErrNotFound, importLocation),
+			http.StatusMethodNotAllowed: ErrNotSupported,
+			http.StatusNotImplemented:   ErrNotSupported,
+		},
+	})
+}
+
+// WaitForProcessing polls the status of the import until it is processed. It returns ErrFailed if the import failed
+// and ErrNotProcessed if it is not processed within the timeout.
+func (c *Client) WaitForProcessing(ctx context.Context, importLocation string, timeout time.Duration) error {
+	pollInterval := c.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	var importStatusResponse ImportStatus
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if c.OnPoll != nil {
+			c.OnPoll()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s/status", c.URL, importLocation), nil)
+		if err != nil {
+			return err
+		}
+
+		c.addHeaders(req)
+		res, err := c.send(req)
+		if err != nil {
+			return err
+		}
+
+		resBody, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		err = res.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		err = decodeResponse(resBody, importStatusSchema, "the import status", &importStatusResponse)
+		if err != nil {
+			return err
+		}
+
+		if importStatusResponse.Status == ImportItemStatusFailed {
+			return ErrFailed
+		}
+		if importStatusResponse.Status == ImportItemStatusProcessed {
+			return nil
+		}
+
+		if err = Sleep(ctx, pollInterval); err != nil {
+			return err
+		}
+	}
+
+	return ErrNotProcessed
+}
+
+// GetAccount returns the account of the API key. It returns ErrUnauthorized if the server rejects the API key.
+func (c *Client) GetAccount(ctx context.Context) (*AccountResponse, error) {
+	var account AccountResponse
+	err := c.do(ctx, http.MethodGet, c.apiPath("/account"), nil, &response{
+		operation: "getting the account",
+		errors: map[int]error{
+			http.StatusUnauthorized: fmt.Errorf("%w: status code %d", ErrUnauthorized, http.StatusUnauthorized),
+			http.StatusForbidden:    fmt.Errorf("%w: status code %d", ErrUnauthorized, http.StatusForbidden),
+		},
+		schema: accountSchema,
+		what:   "the account",
+		value:  &account,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// GetUsage returns the usage of the account in the current billing period. It returns ErrNotSupported if the server
+// doesn't report the usage.
+func (c *Client) GetUsage(ctx context.Context) (*UsageResponse, error) {
+	var usage UsageResponse
+	err := c.do(ctx, http.MethodGet, c.apiPath("/usage"), nil, &response{
+		operation: "getting the usage",
+		errors:    map[int]error{http.StatusNotFound: ErrNotSupported},
+		schema:    usageSchema,
+		what:      "the usage",
+		value:     &usage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// GetVersion returns the version of the server and the API versions it supports. The endpoint is the same in all API
+// versions, so it is used to detect them.
+func (c *Client) GetVersion(ctx context.Context) (*VersionResponse, error) {
+	var version VersionResponse
+	err := c.do(ctx, http.MethodGet, "/api/"+APIVersion1+"/version", nil, &response{
+		operation: "getting the server version",
+		schema:    versionSchema,
+		what:      "the server version",
+		value:     &version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// GetHealth returns the state of the server. It returns ErrNotSupported if the server doesn't report its state.
+func (c *Client) GetHealth(ctx context.Context) (*HealthResponse, error) {
+	var health HealthResponse
+	err := c.do(ctx, http.MethodGet, c.apiPath("/health"), nil, &response{
+		operation: "getting the server health",
+		// A server that is down may answer with 503 and the state in the body.
+		statuses: []int{http.StatusOK, http.StatusServiceUnavailable},
+		errors:   map[int]error{http.StatusNotFound: ErrNotSupported},
+		schema:   healthSchema,
+		what:     "the server health",
+		value:    &health,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &health, nil
+}
+
+// GetCategories returns the categories and subcategories the server accepts. It returns ErrNotSupported if the server
+// doesn't list them.
+func (c *Client) GetCategories(ctx context.Context) (*CategoriesResponse, error) {
+	var categories CategoriesResponse
+	err := c.do(ctx, http.MethodGet, c.apiPath("/categories"), nil, &response{
+		operation: "getting the categories",
+		errors:    map[int]error{http.StatusNotFound: ErrNotSupported},
+		schema:    categoriesSchema,
+		what:      "the categories",
+		value:     &categories,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &categories, nil
+}
+
+// UploadDocument uploads the document of the items, e.g. a datasheet, and returns it. Its ID links it to the items by
+// ImportItemRequest.Documents. It returns ErrNotSupported if the server doesn't accept the documents.
+func (c *Client) UploadDocument(ctx context.Context, name string, content []byte) (*DocumentResponse, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(content); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	var document DocumentResponse
+	err = c.do(ctx, http.MethodPost, c.apiPath("/documents"), &requestBody{data: body.Bytes(), contentType: w.FormDataContentType()}, &response{
+		operation: "uploading the document",
+		statuses:  []int{http.StatusCreated, http.StatusOK},
+		errors: map[int]error{
+			http.StatusNotFound:              ErrNotSupported,
+			http.StatusRequestEntityTooLarge: fmt.Errorf("%w: the document %q is larger than the server accepts", ErrRequestTooLarge, name),
+		},
+		schema: documentSchema,
+		what:   "the document upload",
+		value:  &document,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// requestBody is the body of a request sent with do.
+type requestBody struct {
+	data        []byte
+	contentType string
+}
+
+// response is the expected response of a request sent with do.
+type response struct {
+	operation string        // the request in the StatusError, e.g. "getting the usage"
+	statuses  []int         // status codes of a successful response, http.StatusOK if empty
+	errors    map[int]error // errors of the status codes, e.g. ErrNotSupported of http.StatusNotFound
+	schema    schema        // of the response body, which is decoded into value if it is set
+	what      string        // the response in the errors of the body, e.g. "the usage"
+	value     any
+}
+
+// do sends the request to the path of the server, e.g. "/api/v1/usage" or the location of an import, and decodes the
+// response into out.value. A status code of out.errors returns its error, the other unexpected status codes return a
+// StatusError.
+func (c *Client) do(ctx context.Context, method, path string, body *requestBody, out *response) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body.data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	c.addHeaders(req)
+	if body != nil && body.contentType != "" {
+		req.Header.Set("Content-Type", body.contentType)
+	}
+	res, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	if err, ok := out.errors[res.StatusCode]; ok {
+		return err
+	}
+	statuses := out.statuses
+	if len(statuses) == 0 {
+		statuses = []int{http.StatusOK}
+	}
+	if !slices.Contains(statuses, res.StatusCode) {
+		return &StatusError{Operation: out.operation, StatusCode: res.StatusCode, Body: string(resBody)}
+	}
+	if out.value == nil {
+		return nil
+	}
+
+	return decodeResponse(resBody, out.schema, out.what, out.value)
+}
+
+// send sends the request with the HTTP client of the client.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.HTTPClient == nil {
+		return defaultHTTPClient.Do(req)
+	}
+
+	return c.HTTPClient.Do(req)
+}
+
+// addHeaders adds the headers sent with every request to the server.
+func (c *Client) addHeaders(req *http.Request) {
+	req.Header.Add("Authorization", "Bearer "+strings.TrimPrefix(c.APIKey, "Bearer "))
+	if c.RequestID != "" {
+		req.Header.Add("X-Request-ID", c.RequestID)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+}
+
+// key returns the idempotency key of the import request body.
+func (o *IdempotencyOptions) key(body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(o.Salt))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(body)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Sleep waits for the duration. It returns the context error as soon as the context is canceled. It is the wait of
+// the polling of the imports, e.g. for the programs that poll with their own retries.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
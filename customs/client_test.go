@@ -0,0 +1,54 @@
+package customs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendImportIdempotencyKey(t *testing.T) {
+	request := ImportRequest{ImportItems: []ImportItemRequest{{ID: "1"}}}
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodyHash := sha256.Sum256(body)
+	saltedHash := sha256.Sum256(append([]byte("salt"), body...))
+
+	tests := []struct {
+		name          string
+		idempotency   *IdempotencyOptions
+		gzipThreshold int
+		wantKey       string
+	}{
+		{"disabled", nil, 0, ""},
+		{"enabled", &IdempotencyOptions{}, 0, hex.EncodeToString(bodyHash[:])},
+		{"enabled with the compression", &IdempotencyOptions{}, 1, hex.EncodeToString(bodyHash[:])},
+		{"salted", &IdempotencyOptions{Salt: "salt"}, 0, hex.EncodeToString(saltedHash[:])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var key string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				key = r.Header.Get("Idempotency-Key")
+				w.Header().Set("Location", "/api/v1/items/imports/1")
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "key")
+			client.Idempotency = tt.idempotency
+			client.GzipThreshold = tt.gzipThreshold
+			if _, err := client.SendImport(context.Background(), request); err != nil {
+				t.Fatal(err)
+			}
+			if key != tt.wantKey {
+				t.Errorf("Idempotency-Key = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Package customs is the Go client of the customs API, which determines the commodity codes of the items. It is the
+// library the customs CLI is built on.
+//
+// The package follows semantic versioning together with the module: within a major version, the exported API of
+// this package keeps working, new fields and functions are only added. The rest of the module is the CLI and is not
+// part of the versioned API.
+//
+// Importing items and waiting for their commodity codes:
+//
+//	client := customs.NewClient("https://drotsolutions.com", apiKey)
+//	response, err := client.Import(ctx, customs.ImportRequest{
+//		ImportItems: []customs.ImportItemRequest{{
+//			ID:          "1",
+//			Name:        "Shirt",
+//			Description: "Cotton shirt with long sleeves",
+//			Actions: []customs.ActionRequest{{
+//				Name:       customs.ActionDetermineCommodityCodes,
+//				Parameters: customs.Parameters{CustomsTerritories: []string{"eu", "no"}},
+//			}},
+//		}},
+//	}, 10*time.Minute)
+//	if err != nil {
+//		return err
+//	}
+//	for _, item := range response.ImportItems {
+//		if taric := item.TaricByTerritory("eu"); taric != nil {
+//			fmt.Println(item.ID, taric.Code)
+//		}
+//	}
+//
+// The requests are sent to the API version of Client.APIVersion, v1 by default. NegotiateAPIVersion selects the newest
+// version both the client and the server support.
+//
+// InputReader is the interface of the readers the CLI reads the input files with, so the readers of other sources
+// (e.g. a database) can be shared between the CLI and the programs using the library. ResultWriter is its counterpart
+// for the results. Importer imports all items of an InputReader in chunks and writes their results to a ResultWriter,
+// see the examples.
+package customs
@@ -0,0 +1,100 @@
+package customs_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+func ExampleClient_Import() {
+	client := customs.NewClient("https://drotsolutions.com", os.Getenv("CUSTOMS_API_KEY"))
+	response, err := client.Import(context.Background(), customs.ImportRequest{
+		ImportItems: []customs.ImportItemRequest{{
+			ID:          "1",
+			Name:        "Shirt",
+			Description: "Cotton shirt with long sleeves",
+			Actions: []customs.ActionRequest{{
+				Name:       customs.ActionDetermineCommodityCodes,
+				Parameters: customs.Parameters{CustomsTerritories: []string{"eu", "no"}},
+			}},
+		}},
+	}, 10*time.Minute)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, item := range response.ImportItems {
+		if taric := item.TaricByTerritory("eu"); taric != nil {
+			fmt.Println(item.ID, taric.Code)
+		}
+	}
+}
+
+// itemsReader reads the items of a slice, e.g. the rows of a database query.
+type itemsReader struct {
+	items []customs.ImportItemRequest
+}
+
+func (r *itemsReader) Headings() []string {
+	return []string{"id", "name", "description"}
+}
+
+func (r *itemsReader) Read() (customs.InputItem, error) {
+	if len(r.items) == 0 {
+		return customs.InputItem{}, io.EOF
+	}
+	item := r.items[0]
+	r.items = r.items[1:]
+
+	return customs.InputItem{Item: item, Values: []string{item.ID, item.Name, item.Description}}, nil
+}
+
+func (r *itemsReader) Close() error {
+	return nil
+}
+
+// codesWriter prints the EU commodity codes of the items.
+type codesWriter struct{}
+
+func (codesWriter) Write(item customs.InputItem, result *customs.ImportItemResponse) error {
+	if result == nil {
+		return nil
+	}
+	if taric := result.TaricByTerritory("eu"); taric != nil {
+		fmt.Println(item.Item.ID, taric.Code)
+	}
+
+	return nil
+}
+
+func (codesWriter) Close() error {
+	return nil
+}
+
+func ExampleImporter_Run() {
+	actions := []customs.ActionRequest{{
+		Name:       customs.ActionDetermineCommodityCodes,
+		Parameters: customs.Parameters{CustomsTerritories: []string{"eu"}},
+	}}
+	reader := &itemsReader{items: []customs.ImportItemRequest{
+		{ID: "1", Name: "Shirt", Description: "Cotton shirt with long sleeves", Actions: actions},
+		{ID: "2", Name: "Mug", Description: "Ceramic mug, 300 ml", Actions: actions},
+	}}
+
+	importer := customs.Importer{
+		Client:    customs.NewClient("https://drotsolutions.com", os.Getenv("CUSTOMS_API_KEY")),
+		ChunkSize: 500,
+		Timeout:   10 * time.Minute,
+		OnItemError: func(err *customs.ItemError) error {
+			log.Printf("the item is skipped: %s", err)
+			return nil
+		},
+	}
+	if err := importer.Run(context.Background(), reader, codesWriter{}); err != nil {
+		log.Fatal(err)
+	}
+}
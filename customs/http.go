@@ -0,0 +1,71 @@
+package customs
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultRequestTimeout is the limit of a single request of the clients without their own HTTP client.
+const DefaultRequestTimeout = time.Minute
+
+const (
+	dialTimeout           = 10 * time.Second
+	keepAlive             = 30 * time.Second
+	tlsHandshakeTimeout   = 10 * time.Second
+	idleConnTimeout       = 90 * time.Second
+	expectContinueTimeout = time.Second
+	maxConnsPerHost       = 10
+)
+
+// HTTPClientOptions configures the HTTP client returned by NewHTTPClient. The zero values are usable defaults.
+type HTTPClientOptions struct {
+	RequestTimeout time.Duration // DefaultRequestTimeout if zero
+	// Proxy is used for all requests if set, otherwise the proxy is taken from the HTTP_PROXY, HTTPS_PROXY and
+	// NO_PROXY environment variables. The credentials of an authenticated proxy are part of the URL.
+	Proxy *url.URL
+	// TLSConfig is the configuration of the TLS connections, e.g. with the client certificate of the mutual TLS.
+	TLSConfig *tls.Config
+}
+
+// defaultHTTPClient sends the requests of the clients without their own HTTP client.
+var defaultHTTPClient = NewHTTPClient(HTTPClientOptions{})
+
+// NewHTTPClient returns the HTTP client for the server requests. Besides the overall request timeout, every phase of
+// the request has its own limit, so a server or network that stops responding can't block the caller.
+func NewHTTPClient(options HTTPClientOptions) *http.Client {
+	requestTimeout := options.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = DefaultRequestTimeout
+	}
+	proxy := http.ProxyFromEnvironment
+	if options.Proxy != nil {
+		proxy = http.ProxyURL(options.Proxy)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: keepAlive,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSClientConfig:       options.TLSConfig,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ResponseHeaderTimeout: requestTimeout,
+		ExpectContinueTimeout: expectContinueTimeout,
+		IdleConnTimeout:       idleConnTimeout,
+		MaxIdleConns:          maxConnsPerHost,
+		MaxIdleConnsPerHost:   maxConnsPerHost,
+		MaxConnsPerHost:       maxConnsPerHost,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   requestTimeout,
+	}
+}
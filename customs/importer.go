@@ -0,0 +1,101 @@
+package customs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// DefaultChunkSize is the number of the items sent in one import by the importers without their own chunk size.
+const DefaultChunkSize = 1000
+
+// Importer imports all items of an input and writes their results to an output, in the order of the input. The items
+// are sent in imports of ChunkSize items, one after the other. The zero values of the optional fields are usable
+// defaults.
+type Importer struct {
+	Client *Client
+
+	ChunkSize int           // DefaultChunkSize if zero
+	Timeout   time.Duration // limit of the processing of every import
+	// OnItemError is called for an invalid input item. The import stops with the error it returns, so nil skips the
+	// item. Without it, the import stops at the first invalid item.
+	OnItemError func(err *ItemError) error
+}
+
+// Run reads the items from the reader, imports them and writes their results to the writer. An import that is failed
+// or not processed in time stops the run with ErrFailed or ErrNotProcessed, after the results of its processed items
+// are written. The reader and the writer are not closed.
+func (i *Importer) Run(ctx context.Context, reader InputReader, writer ResultWriter) error {
+	chunkSize := i.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	var chunk []InputItem
+	imported := 0
+	for {
+		item, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var itemErr *ItemError
+		if errors.As(err, &itemErr) && i.OnItemError != nil {
+			if err = i.OnItemError(itemErr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		chunk = append(chunk, item)
+		if !item.Skipped {
+			imported++
+		}
+		if imported == chunkSize {
+			if err = i.importChunk(ctx, chunk, writer); err != nil {
+				return err
+			}
+			chunk, imported = nil, 0
+		}
+	}
+
+	return i.importChunk(ctx, chunk, writer)
+}
+
+// importChunk imports the items of the chunk and writes them with their results.
+func (i *Importer) importChunk(ctx context.Context, chunk []InputItem, writer ResultWriter) error {
+	var request ImportRequest
+	for _, item := range chunk {
+		if !item.Skipped {
+			request.ImportItems = append(request.ImportItems, item.Item)
+		}
+	}
+
+	var importErr error
+	results := make(map[string]*ImportItemResponse)
+	if len(request.ImportItems) > 0 {
+		var response *ImportResponse
+		response, importErr = i.Client.Import(ctx, request, i.Timeout)
+		if response == nil {
+			return importErr
+		}
+		for j := range response.ImportItems {
+			results[response.ImportItems[j].ID] = &response.ImportItems[j]
+		}
+	}
+
+	for _, item := range chunk {
+		var result *ImportItemResponse
+		if !item.Skipped {
+			result = results[item.Item.ID]
+		}
+		if err := writer.Write(item, result); err != nil {
+			return err
+		}
+	}
+
+	return importErr
+}
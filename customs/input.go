@@ -0,0 +1,52 @@
+package customs
+
+import "fmt"
+
+// InputReader reads the items to import from an input. Read returns io.EOF after the last item, and *ItemError for
+// an invalid item.
+type InputReader interface {
+	// Headings returns the names of the input columns.
+	Headings() []string
+	Read() (InputItem, error)
+	Close() error
+}
+
+// InputItem is an item read from the input, together with the input values it was read from.
+type InputItem struct {
+	Item   ImportItemRequest
+	Values []string // input values in the order of the headings
+	Source SourceLocation
+
+	// Skipped is set for a row without an item, e.g. a blank row or a totals row below the items. The row is kept in
+	// the outputs, but it is not imported and its Item is empty.
+	Skipped bool
+}
+
+// SourceLocation points to the place in the input an item is read from, so the errors can point the operator to it.
+type SourceLocation struct {
+	Input string
+	Sheet string // empty for the inputs without sheets
+	Row   int    // 1 indexed, the headings are the row 1
+}
+
+func (l SourceLocation) String() string {
+	if l.Sheet != "" {
+		return fmt.Sprintf("%s, sheet %q, row %d", l.Input, l.Sheet, l.Row)
+	}
+
+	return fmt.Sprintf("%s, row %d", l.Input, l.Row)
+}
+
+// ItemError is returned by the input readers for an invalid item. The reading can continue with the next item.
+type ItemError struct {
+	Source SourceLocation
+	Err    error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Source, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
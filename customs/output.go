@@ -0,0 +1,10 @@
+package customs
+
+// ResultWriter writes the results of the imported items to an output, e.g. a file or a database. It is the counterpart
+// of InputReader.
+type ResultWriter interface {
+	// Write writes the item read from the input with its result. The result is nil for the skipped rows and for the
+	// items the server returned no result for.
+	Write(item InputItem, result *ImportItemResponse) error
+	Close() error
+}
@@ -0,0 +1,230 @@
+package customs
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxSchemaProblems is the number of the schema mismatches reported of a response, the rest are only counted.
+const maxSchemaProblems = 10
+
+// schema describes the expected shape of a JSON value of a server response, so a response changed by the server is
+// reported precisely instead of being decoded into zero values. The fields not in the schema are allowed, so the
+// server can add fields without breaking the client.
+type schema struct {
+	kind   string           // one of the JSON kinds, e.g. "object", any kind if empty
+	fields map[string]field // of an object
+	items  *schema          // of an array
+}
+
+// field is a field of an object schema. An optional field may be missing or null.
+type field struct {
+	schema
+	required bool
+}
+
+var (
+	anyValue    = schema{}
+	stringValue = schema{kind: "string"}
+	numberValue = schema{kind: "number"}
+)
+
+func object(fields map[string]field) schema {
+	return schema{kind: "object", fields: fields}
+}
+
+func arrayOf(items schema) schema {
+	return schema{kind: "array", items: &items}
+}
+
+func required(s schema) field {
+	return field{schema: s, required: true}
+}
+
+func optional(s schema) field {
+	return field{schema: s}
+}
+
+var importResponseSchema = object(map[string]field{
+	"id": required(stringValue),
+	"items": required(arrayOf(object(map[string]field{
+		"id":          required(stringValue),
+		"name":        optional(stringValue),
+		"description": optional(stringValue),
+		"actions": optional(arrayOf(object(map[string]field{
+			"name":        required(stringValue),
+			"status":      required(stringValue),
+			"parameters":  optional(object(nil)),
+			"error":       optional(stringValue),
+			"attempts":    optional(numberValue),
+			"maxAttempts": optional(numberValue),
+			"output":      optional(object(nil)),
+		}))),
+		// The codes are missing for the items without the classification, e.g. of the describe action, and for the
+		// pending or failed items, and a code is missing for a territory without one.
+		"commodityCodes": optional(arrayOf(object(map[string]field{
+			"customsTerritory":  required(stringValue),
+			"code":              optional(stringValue),
+			"description":       optional(stringValue),
+			"dutyRate":          optional(stringValue),
+			"vatRate":           optional(stringValue),
+			"supplementaryUnit": optional(stringValue),
+			"confidence":        optional(numberValue),
+			"explanation":       optional(stringValue),
+			"nomenclaturePath":  optional(arrayOf(stringValue)),
+			"measures": optional(arrayOf(object(map[string]field{
+				"type": required(stringValue),
+			}))),
+		}))),
+		"warnings":  optional(arrayOf(anyValue)),
+		"createdAt": optional(stringValue),
+		"updatedAt": optional(stringValue),
+	}))),
+	"createdAt": optional(stringValue),
+	"updatedAt": optional(stringValue),
+})
+
+var importStatusSchema = object(map[string]field{
+	"status": required(stringValue),
+})
+
+var accountSchema = object(map[string]field{
+	"customsTerritories": required(arrayOf(stringValue)),
+	"permissions":        optional(arrayOf(stringValue)),
+	"pricePerItem":       optional(numberValue),
+	"remainingItems":     optional(numberValue),
+})
+
+var usageSchema = object(map[string]field{
+	"itemsUsed":      required(numberValue),
+	"itemsQuota":     optional(numberValue),
+	"remainingItems": optional(numberValue),
+	"rateLimits": optional(arrayOf(object(map[string]field{
+		"limit":     required(numberValue),
+		"remaining": optional(numberValue),
+		"window":    required(stringValue),
+	}))),
+})
+
+var versionSchema = object(map[string]field{
+	"version":           required(stringValue),
+	"minimumCliVersion": optional(stringValue),
+	"apiVersions":       optional(arrayOf(stringValue)),
+})
+
+var healthSchema = object(map[string]field{
+	"status":  required(stringValue),
+	"message": optional(stringValue),
+})
+
+var categoriesSchema = object(map[string]field{
+	"categories": required(arrayOf(object(map[string]field{
+		"name":          required(stringValue),
+		"subcategories": optional(arrayOf(stringValue)),
+	}))),
+})
+
+var documentSchema = object(map[string]field{
+	"id": required(stringValue),
+})
+
+// decodeResponse checks the response body against the schema and decodes it into v. A body that doesn't match is
+// reported with ErrInvalidResponse and the paths of the mismatches, e.g. "items[3].actions[0].status missing".
+func decodeResponse(body []byte, s schema, what string, v any) error {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%w of %s: %s", ErrInvalidResponse, what, err)
+	}
+
+	problems := s.validate(value, "")
+	if len(problems) > maxSchemaProblems {
+		problems = append(problems[:maxSchemaProblems], fmt.Sprintf("%d more", len(problems)-maxSchemaProblems))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w of %s: %s", ErrInvalidResponse, what, strings.Join(problems, ", "))
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// validate returns the mismatches of the value, which is at the path of the response.
+func (s schema) validate(value any, path string) []string {
+	if s.kind == "" {
+		return nil
+	}
+	if kind := jsonKind(value); kind != s.kind {
+		return []string{fmt.Sprintf("%s is %s, expected %s", displayPath(path), withArticle(kind), withArticle(s.kind))}
+	}
+
+	var problems []string
+	switch s.kind {
+	case "object":
+		values := value.(map[string]any)
+		names := make([]string, 0, len(s.fields))
+		for name := range s.fields {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			f := s.fields[name]
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			fieldValue, ok := values[name]
+			switch {
+			case !ok && f.required:
+				problems = append(problems, fieldPath+" missing")
+			case fieldValue == nil && f.required:
+				problems = append(problems, fieldPath+" is null")
+			case fieldValue != nil:
+				problems = append(problems, f.validate(fieldValue, fieldPath)...)
+			}
+		}
+	case "array":
+		for i, item := range value.([]any) {
+			problems = append(problems, s.items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return problems
+}
+
+// jsonKind returns the kind of the value decoded from JSON.
+func jsonKind(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "the response"
+	}
+
+	return path
+}
+
+func withArticle(kind string) string {
+	switch kind {
+	case "null":
+		return kind
+	case "object", "array":
+		return "an " + kind
+	default:
+		return "a " + kind
+	}
+}
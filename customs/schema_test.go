@@ -0,0 +1,59 @@
+package customs
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema schema
+		value  string
+		want   []string
+	}{
+		{"valid status", importStatusSchema, `{"status": "processed"}`, nil},
+		{"unknown fields", importStatusSchema, `{"status": "processed", "progress": 0.5}`, nil},
+		{"missing field", importStatusSchema, `{}`, []string{"status missing"}},
+		{"null field", importStatusSchema, `{"status": null}`, []string{"status is null"}},
+		{"wrong kind", importStatusSchema, `{"status": 1}`, []string{"status is a number, expected a string"}},
+		{"not an object", importStatusSchema, `[]`, []string{"the response is an array, expected an object"}},
+		{"null response", importStatusSchema, `null`, []string{"the response is null, expected an object"}},
+		{"optional fields", accountSchema, `{"customsTerritories": ["eu"], "permissions": null}`, nil},
+		{"array items", accountSchema, `{"customsTerritories": ["eu", 2, true]}`, []string{
+			"customsTerritories[1] is a number, expected a string",
+			"customsTerritories[2] is a boolean, expected a string",
+		}},
+		{"nested path", importResponseSchema, `{"id": "1", "items": [{"id": "1", "commodityCodes": [{"customsTerritory": "eu", "code": 6205200000}]}]}`, []string{
+			"items[0].commodityCodes[0].code is a number, expected a string",
+		}},
+		{"item without the codes", importResponseSchema, `{"id": "1", "items": [{"id": "1", "actions": [{"name": "enrichDescription", "status": "processed"}]}]}`, nil},
+		{"pending item", importResponseSchema, `{"id": "1", "items": [{"id": "1", "actions": [{"name": "determineCommodityCodes", "status": "pending"}], "commodityCodes": null}]}`, nil},
+		{"failed item", importResponseSchema, `{"id": "1", "items": [{"id": "1", "actions": [{"name": "determineCommodityCodes", "status": "failed", "error": "no code"}], "commodityCodes": []}]}`, nil},
+		{"territory without a code", importResponseSchema, `{"id": "1", "items": [{"id": "1", "commodityCodes": [{"customsTerritory": "no", "code": null}]}]}`, nil},
+		{"code without a territory", importResponseSchema, `{"id": "1", "items": [{"id": "1", "commodityCodes": [{"code": "6205200000"}]}]}`, []string{
+			"items[0].commodityCodes[0].customsTerritory missing",
+		}},
+		{"missing items", importResponseSchema, `{"id": "1"}`, []string{"items missing"}},
+		{"action without a status", importResponseSchema, `{"id": "1", "items": [{"id": "1", "actions": [{"name": "determineCommodityCodes"}]}]}`, []string{
+			"items[0].actions[0].status missing",
+		}},
+		{"sorted problems", usageSchema, `{"rateLimits": [{}]}`, []string{
+			"itemsUsed missing",
+			"rateLimits[0].limit missing",
+			"rateLimits[0].window missing",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.schema.validate(value, ""); !slices.Equal(got, tt.want) {
+				t.Errorf("validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
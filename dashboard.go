@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// dashboardInterval is how often the dashboard is redrawn.
+	dashboardInterval = time.Second
+	// dashboardErrors is the number of the failed items listed on the dashboard.
+	dashboardErrors = 10
+	// dashboardMessages is the number of the latest messages and warnings listed on the dashboard.
+	dashboardMessages = 5
+)
+
+const (
+	dashboardStatusWaiting = "waiting"
+	dashboardStatusSent    = "processing"
+	dashboardStatusDone    = "done"
+	dashboardStatusFailed  = "failed"
+)
+
+// board is the dashboard of the running import, nil if the dashboard is not shown. Its methods do nothing on nil, so
+// the import reports to it unconditionally.
+var board *dashboard
+
+// dashboard redraws the state of the imports of an input in the terminal, for the operators watching large imports.
+// While it is shown, the console messages and the log records are written to the dashboard, which lists the latest of
+// them, so only the dashboard writes to the terminal and the redraws are not torn.
+type dashboard struct {
+	mu        sync.Mutex
+	out       io.Writer
+	console   io.Writer // the console to restore when the dashboard stops
+	logOutput io.Writer // the log output to restore when the dashboard stops
+	input     string
+	started   time.Time
+	imports   []dashboardImport
+	errors    []string
+	messages  []string
+	partial   []byte // the written message without the line end yet
+	stopped   chan struct{}
+	done      sync.WaitGroup
+}
+
+type dashboardImport struct {
+	location  string
+	items     int
+	status    string
+	processed int
+	failed    int
+}
+
+// isTerminal reports whether the writer is a terminal the dashboard can be drawn in.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// startDashboard shows the dashboard of the imports of the input until it is stopped.
+func startDashboard(input string, imports []ImportRequest) *dashboard {
+	d := &dashboard{
+		out:       console,
+		console:   console,
+		logOutput: logOutput,
+		input:     input,
+		started:   time.Now(),
+		imports:   make([]dashboardImport, len(imports)),
+		stopped:   make(chan struct{}),
+	}
+	for i, imp := range imports {
+		d.imports[i] = dashboardImport{items: len(imp.ImportItems), status: dashboardStatusWaiting}
+	}
+	console = d
+	if isTerminal(logOutput) {
+		logOutput = d
+		setupLogger()
+	}
+
+	d.done.Add(1)
+	go func() {
+		defer d.done.Done()
+		ticker := time.NewTicker(dashboardInterval)
+		defer ticker.Stop()
+		for {
+			d.render()
+			select {
+			case <-ticker.C:
+			case <-d.stopped:
+				d.render()
+				return
+			}
+		}
+	}()
+
+	return d
+}
+
+// stop draws the final state of the dashboard and gives the console back to the progress messages.
+func (d *dashboard) stop() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	select {
+	case <-d.stopped:
+		d.mu.Unlock()
+		return
+	default:
+		close(d.stopped)
+	}
+	d.mu.Unlock()
+
+	d.done.Wait()
+	console = d.console
+	if logOutput == d {
+		logOutput = d.logOutput
+		setupLogger()
+	}
+}
+
+// Write adds the lines of the console messages and the log records to the dashboard. The progress dots and the other
+// text without a line end are not listed.
+func (d *dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.partial = append(d.partial, p...)
+	for {
+		line, rest, found := bytes.Cut(d.partial, []byte("\n"))
+		if !found {
+			break
+		}
+		d.partial = rest
+		if message := strings.TrimLeft(strings.TrimSpace(string(line)), "."); message != "" {
+			d.messages = append(d.messages, message)
+		}
+	}
+	if len(d.messages) > dashboardMessages {
+		d.messages = d.messages[len(d.messages)-dashboardMessages:]
+	}
+
+	return len(p), nil
+}
+
+func (d *dashboard) sent(i int, importLocation string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.imports[i].location = importLocation
+	d.imports[i].status = dashboardStatusSent
+}
+
+// finished counts the results of the import and collects the errors of its failed items.
+func (d *dashboard) finished(i int, result importResult) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	imp := &d.imports[i]
+	imp.location = result.location
+	if result.err != nil || result.response == nil {
+		imp.status = dashboardStatusFailed
+		return
+	}
+	imp.status = dashboardStatusDone
+	for _, item := range result.response.ImportItems {
+		switch status, message := itemOutcome(item, tr("unknown error")); status {
+		case ImportItemStatusProcessed:
+			imp.processed++
+		case ImportItemStatusFailed:
+			imp.failed++
+			d.errors = append(d.errors, fmt.Sprintf("%s: %s", item.ID, message))
+		}
+	}
+}
+
+func (d *dashboard) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var b strings.Builder
+	// Move to the top left corner and clear the screen.
+	b.WriteString("\033[H\033[2J")
+	elapsed := time.Since(d.started).Round(time.Second)
+	fmt.Fprint(&b, trf("Import of %q, run ID %s, elapsed %s", d.input, runID, elapsed)+"\n\n")
+	fmt.Fprintf(&b, "%-4s %-40s %8s  %-12s %10s %8s\n", "#", tr("Import"), tr("Items"), tr("Status"), tr("Processed"), tr("Failed"))
+
+	items, done, processed, failed := 0, 0, 0, 0
+	for i, imp := range d.imports {
+		location := imp.location
+		if location == "" {
+			location = "-"
+		}
+		// The status is padded before it is colored, the color codes don't take any room on the screen.
+		status := colorize(d.out, statusColor(imp.status), fmt.Sprintf("%-12s", tr(imp.status)))
+		fmt.Fprintf(&b, "%-4d %-40s %8d  %s %10d %8d\n", i+1, location, imp.items, status, imp.processed, imp.failed)
+		items += imp.items
+		if imp.status == dashboardStatusDone || imp.status == dashboardStatusFailed {
+			done += imp.items
+		}
+		processed += imp.processed
+		failed += imp.failed
+	}
+	fmt.Fprint(&b, "\n"+trf("Items: %d of %d done, %d processed, %d failed", done, items, processed, failed)+"\n")
+
+	if len(d.errors) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", tr("Failed items:"))
+		for _, message := range d.errors[:min(len(d.errors), dashboardErrors)] {
+			fmt.Fprintf(&b, "  %s\n", message)
+		}
+		if len(d.errors) > dashboardErrors {
+			fmt.Fprint(&b, "  "+trf("and %d more", len(d.errors)-dashboardErrors)+"\n")
+		}
+	}
+	if len(d.messages) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", tr("Messages:"))
+		for _, message := range d.messages {
+			fmt.Fprintf(&b, "  %s\n", message)
+		}
+	}
+	fmt.Fprintf(&b, "\n%s\n", tr("Ctrl+C cancels the run, the results received so far are written to the output files."))
+
+	_, _ = io.WriteString(d.out, b.String())
+}
+
+// failedItems returns the items of the import whose commodity codes failed for any of the territories, so they can be
+// sent again.
+func failedItems(imp ImportRequest, doc *ResultDocument) []ImportItemRequest {
+	var items []ImportItemRequest
+	for _, item := range imp.ImportItems {
+		result, ok := doc.results[item.ID]
+		if !ok {
+			continue
+		}
+		for _, territoryResult := range result.Territories {
+			if territoryResult.Status == ImportItemStatusFailed {
+				items = append(items, item)
+				break
+			}
+		}
+	}
+
+	return items
+}
+
+// retryFailedItems offers to send the failed items of the import again and merges their new results into the document.
+// The items are sent with the tokens of the anonymous IDs of the run, nil without --anonymize.
+func retryFailedItems(ctx context.Context, imp ImportRequest, doc *ResultDocument, anonymous *anonymizedIDs) error {
+	items := failedItems(imp, doc)
+	if len(items) == 0 || !isInteractive() {
+		return nil
+	}
+	answer, err := ask(trf("Retry the %d failed items? [y/N]:", len(items)))
+	if err != nil || !isYes(answer) {
+		return nil
+	}
+
+	imports := []ImportRequest{{ImportItems: items}}
+	if chunkSize > 0 {
+		imports = splitImportByChunks(imports, chunkSize)
+	}
+	imports, err = splitImportBySize(imports, maxRequestSize)
+	if err != nil {
+		return err
+	}
+	for _, retry := range imports {
+		retry = anonymous.request(retry)
+		// The failed items may be the whole import, the salt keeps the server from returning the failed import again.
+		importLocation, err := sendImportRequest(ctx, retry, runID+"-retry", url, apiKey)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(console, trf("The import has been sent for processing (import URL: %s%s)", url, importLocation))
+		result := awaitImport(ctx, url, retry, importLocation, apiKey, timeout)
+		if result.err != nil {
+			return result.err
+		}
+		anonymous.restore(result.response)
+		doc.addImport(importLocation)
+		for _, item := range result.response.ImportItems {
+			if err = doc.add(item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrDeprecated = fmt.Errorf("deprecated API")
+
+// reportedWarnings holds the server warnings already printed, because the same warning is usually returned on every
+// status poll.
+var reportedWarnings sync.Map
+
+// checkDeprecation prints the deprecation notices and the warnings returned by the server in the response headers.
+// In the strict mode, a deprecated endpoint fails the run.
+func checkDeprecation(res *http.Response) error {
+	endpoint := fmt.Sprintf("%s %s", res.Request.Method, res.Request.URL.Path)
+
+	for _, warning := range res.Header.Values("Warning") {
+		reportWarning(fmt.Sprintf("the server returned a warning for %s: %s", endpoint, parseWarning(warning)))
+	}
+
+	deprecation := res.Header.Get("Deprecation")
+	sunset := res.Header.Get("Sunset")
+	if deprecation == "" && sunset == "" {
+		return nil
+	}
+
+	message := fmt.Sprintf("the API endpoint %s is deprecated", endpoint)
+	if since := parseDeprecationDate(deprecation); since != "" {
+		message += " since " + since
+	}
+	if sunsetAt, err := http.ParseTime(sunset); err == nil {
+		message += " and will be removed on " + sunsetAt.Format(time.DateOnly)
+	}
+	if link := getLinkByRelation(res.Header, "deprecation"); link != "" {
+		message += ", more details: " + link
+	} else if link = getLinkByRelation(res.Header, "sunset"); link != "" {
+		message += ", more details: " + link
+	}
+	message += ". Please update the customs CLI or contact the support."
+
+	if strict {
+		return fmt.Errorf("%w: %s", ErrDeprecated, message)
+	}
+	reportWarning(message)
+
+	return nil
+}
+
+func reportWarning(message string) {
+	if _, reported := reportedWarnings.LoadOrStore(message, true); !reported {
+		warnf("%s", message)
+	}
+}
+
+// parseWarning returns the text of the Warning header, e.g. `299 - "Field weightUnit is deprecated"`.
+func parseWarning(warning string) string {
+	first := strings.Index(warning, `"`)
+	last := strings.LastIndex(warning, `"`)
+	if first == -1 || first == last {
+		return warning
+	}
+
+	return warning[first+1 : last]
+}
+
+// parseDeprecationDate returns the date of the Deprecation header. The header is either a Unix timestamp
+// (e.g. "@1688169599"), an HTTP date, or just "true" in the older servers.
+func parseDeprecationDate(deprecation string) string {
+	if timestamp, err := strconv.ParseInt(strings.TrimPrefix(deprecation, "@"), 10, 64); err == nil {
+		return time.Unix(timestamp, 0).UTC().Format(time.DateOnly)
+	}
+	if date, err := http.ParseTime(deprecation); err == nil {
+		return date.Format(time.DateOnly)
+	}
+
+	return ""
+}
+
+// getLinkByRelation returns the URL of the Link header with the given relation type, e.g.
+// `<https://drotsolutions.com/deprecations>; rel="deprecation"`.
+func getLinkByRelation(header http.Header, relation string) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			for _, param := range parts[1:] {
+				if strings.EqualFold(strings.TrimSpace(param), fmt.Sprintf("rel=%q", relation)) {
+					return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+				}
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// reservedTemplateColumns are the item columns the description template can't use. They are not only text of the
+// item, e.g. a "model" column is sent as the classification model, so a column added for the template under such a
+// name would change the import.
+var reservedTemplateColumns = []string{"id", "description file", "customs territories", "model", "actions", "documents"}
+
+// parseDescriptionTemplate parses the --description-template, e.g. "{{.name}} {{.colour}} made of {{.material}}". The
+// columns without a value are empty, so an optional column can be used in the template.
+func parseDescriptionTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("description").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid description template: %w", err)
+	}
+	for _, column := range templateColumns(tmpl) {
+		for _, reserved := range reservedTemplateColumns {
+			if matchesHeading(column, reserved) {
+				return nil, fmt.Errorf("invalid description template: %q is the %q column of the item, use another heading for the column", column, reserved)
+			}
+		}
+	}
+
+	return tmpl, nil
+}
+
+// templateColumns returns the columns the template uses, in lower case: the fields of the dot, e.g. "material" of
+// {{.material}}, and the keys of the index function, e.g. "country of origin" of {{index . "country of origin"}}.
+func templateColumns(tmpl *template.Template) []string {
+	var columns []string
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			if len(n.Args) == 3 && n.Args[0].String() == "index" && n.Args[1].Type() == parse.NodeDot {
+				if key, ok := n.Args[2].(*parse.StringNode); ok {
+					columns = append(columns, strings.ToLower(key.Text))
+				}
+			}
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			columns = append(columns, strings.ToLower(n.Ident[0]))
+		}
+	}
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root)
+	}
+	slices.Sort(columns)
+
+	return slices.Compact(columns)
+}
+
+// isTemplateColumn reports whether the --description-template uses the column of the heading, so the column is known
+// to --strict-columns.
+func isTemplateColumn(heading string) bool {
+	if descriptionTmpl == nil {
+		return false
+	}
+
+	return slices.Contains(templateColumns(descriptionTmpl), strings.ToLower(strings.TrimSpace(heading)))
+}
+
+// templateValues returns the values of the row for the description template by the heading in lower case. The
+// columns of the item fields are also available by the canonical heading, e.g. "name" for the "product name" column.
+// A heading with spaces is used with the index function, e.g. {{index . "country of origin"}}.
+func templateValues(headings []string, row []string, matched []matchedColumn) map[string]string {
+	values := map[string]string{}
+	for i, heading := range headings {
+		if heading = strings.ToLower(strings.TrimSpace(heading)); heading != "" {
+			values[heading] = strings.TrimSpace(getCell(row, i))
+		}
+	}
+	for _, column := range matched {
+		if column.index != nil {
+			values[column.name] = strings.TrimSpace(getCell(row, *column.index))
+		}
+	}
+
+	return values
+}
+
+// executeDescriptionTemplate composes the description of the row. The whitespace is collapsed, so the empty columns
+// leave no double spaces.
+func executeDescriptionTemplate(tmpl *template.Template, values map[string]string) (string, error) {
+	var description strings.Builder
+	if err := tmpl.Execute(&description, values); err != nil {
+		return "", fmt.Errorf("invalid description template: %w", err)
+	}
+
+	return strings.Join(strings.Fields(description.String()), " "), nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseDescriptionTemplate(t *testing.T) {
+	tests := []struct {
+		text        string
+		wantColumns []string
+		wantErr     bool
+	}{
+		{"{{.name}} {{.colour}} made of {{.material}}", []string{"colour", "material", "name"}, false},
+		{`{{.Name}} from {{index . "country of origin"}}`, []string{"country of origin", "name"}, false},
+		{"{{if .size}}size {{.size}}{{else}}{{.fit}}{{end}}", []string{"fit", "size"}, false},
+		{"{{with .brand}}{{.}}{{end}} {{.description}}", []string{"brand", "description"}, false},
+		{"no columns", nil, false},
+		{"{{.name}} {{.model}}", nil, true},
+		{`{{index . "Customs territories"}}`, nil, true},
+		{"{{.modell}}", nil, true},
+		{"{{.name", nil, true},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseDescriptionTemplate(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDescriptionTemplate(%q) error = %v, want error %t", tt.text, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := templateColumns(tmpl); !slices.Equal(got, tt.wantColumns) {
+			t.Errorf("templateColumns(%q) = %q, want %q", tt.text, got, tt.wantColumns)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// codeDetail is a detail of the commodity code written to a column next to the result column of every customs
+// territory, e.g. the nomenclature description of the code in the "description EU" column.
+type codeDetail struct {
+	heading string                                // heading of the column without the territory
+	include string                                // detail the server returns only on request, empty if always returned
+	value   func(r *TerritoryResult) string       // cell of the column
+	parse   func(r *TerritoryResult, cell string) // sets the detail from the cell of a result file
+}
+
+// codeDetails are the detail columns of the outputs, in the order they follow the result column.
+var codeDetails = []codeDetail{
+	{
+		heading: "description",
+		value:   func(r *TerritoryResult) string { return r.Description },
+		parse:   func(r *TerritoryResult, cell string) { r.Description = cell },
+	},
+	{
+		heading: "supplementary unit",
+		value:   func(r *TerritoryResult) string { return r.SupplementaryUnit },
+		parse:   func(r *TerritoryResult, cell string) { r.SupplementaryUnit = cell },
+	},
+	{
+		heading: "confidence",
+		value:   formatConfidence,
+		parse:   parseConfidence,
+	},
+	{
+		heading: "duty rate",
+		include: includeRates,
+		value:   func(r *TerritoryResult) string { return r.DutyRate },
+		parse:   func(r *TerritoryResult, cell string) { r.DutyRate = cell },
+	},
+	{
+		heading: "VAT rate",
+		include: includeRates,
+		value:   func(r *TerritoryResult) string { return r.VATRate },
+		parse:   func(r *TerritoryResult, cell string) { r.VATRate = cell },
+	},
+	{
+		heading: "measures",
+		include: includeMeasures,
+		value:   formatMeasures,
+		parse:   parseMeasures,
+	},
+	{
+		heading: "explanation",
+		include: includeExplanation,
+		value:   func(r *TerritoryResult) string { return r.Explanation },
+		parse:   func(r *TerritoryResult, cell string) { r.Explanation = cell },
+	},
+	{
+		heading: "nomenclature path",
+		include: includeExplanation,
+		value:   func(r *TerritoryResult) string { return strings.Join(r.NomenclaturePath, nomenclaturePathSeparator) },
+		parse:   parseNomenclaturePath,
+	},
+}
+
+// nomenclaturePathSeparator separates the levels of the nomenclature path in the outputs, e.g. "Chapter 62 > 6205".
+const nomenclaturePathSeparator = " > "
+
+// measureTypes are the names of the trade measure types in the outputs.
+var measureTypes = map[string]string{
+	MeasureTypeAntiDumping: "anti-dumping duty",
+	MeasureTypeQuota:       "quota",
+	MeasureTypeLicence:     "licence",
+	MeasureTypeProhibition: "prohibition",
+}
+
+// formatMeasures returns the cell of the trade measures of the code, e.g. "anti-dumping duty: 48.5% for China; licence".
+func formatMeasures(r *TerritoryResult) string {
+	cells := make([]string, len(r.Measures))
+	for i, measure := range r.Measures {
+		cell := measure.Type
+		if name, ok := measureTypes[measure.Type]; ok {
+			cell = trOutput(name)
+		}
+		if measure.Description != "" {
+			cell += ": " + measure.Description
+		}
+		cells[i] = cell
+	}
+
+	return strings.Join(cells, "; ")
+}
+
+// parseMeasures sets the trade measures from the cell of a result file. The type names are kept in the descriptions.
+func parseMeasures(r *TerritoryResult, cell string) {
+	r.Measures = nil
+	for _, measure := range strings.Split(cell, "; ") {
+		r.Measures = append(r.Measures, MeasureResponse{Description: measure})
+	}
+}
+
+// parseNomenclaturePath sets the nomenclature path from the cell of a result file.
+func parseNomenclaturePath(r *TerritoryResult, cell string) {
+	r.NomenclaturePath = nil
+	if cell != "" {
+		r.NomenclaturePath = strings.Split(cell, nomenclaturePathSeparator)
+	}
+}
+
+// detailColumn is the column of a code detail of a customs territory.
+type detailColumn struct {
+	territory string
+	detail    codeDetail
+	index     int
+}
+
+// key returns the untranslated heading of the detail column of the customs territory, e.g. "description EU".
+func (d codeDetail) key(territory string) string {
+	return d.heading + " " + strings.ToUpper(territory)
+}
+
+// includedDetails returns the code details requested from the server by the flags, e.g. the rates with --rates.
+func includedDetails() []string {
+	var include []string
+	if rates {
+		include = append(include, includeRates)
+	}
+	if measures {
+		include = append(include, includeMeasures)
+	}
+	if explanations {
+		include = append(include, includeExplanation)
+	}
+
+	return include
+}
+
+// requestedCodeDetails returns the code details written to the outputs of the run: the details the server always
+// returns and the requested ones.
+func requestedCodeDetails() []codeDetail {
+	var details []codeDetail
+	for _, detail := range codeDetails {
+		if detail.include == "" || slices.Contains(includedDetails(), detail.include) {
+			details = append(details, detail)
+		}
+	}
+
+	return details
+}
+
+// resultLayout holds the indexes of the result columns of a document.
+type resultLayout struct {
+	resultColumns   map[string]int // by the customs territory
+	modelColumns    map[string]int // results of the second model of --compare-models by the customs territory
+	actionColumns   map[string]int // results of the --extra-actions by the action name
+	detailColumns   []detailColumn
+	reviewColumn    int // -1 if the trade measures are not written
+	changedColumn   int // -1 without --revalidate
+	agreementColumn int // -1 without --compare-models
+	updatedColumn   int
+	warningColumn   int
+}
+
+// appendResultColumns appends the result columns to the headings: the result, the result of the second model with
+// --compare-models and the code details of every customs territory, the results of the --extra-actions, the compliance
+// review flag with the trade measures, the changed codes with --revalidate, the agreement of the models, the time of the
+// update and the warnings. The result columns the headings already have are used again, e.g. of the input updated in
+// place.
+func appendResultColumns(headings *[]string, details []codeDetail, actions []string) resultLayout {
+	layout := resultLayout{
+		resultColumns:   map[string]int{},
+		modelColumns:    map[string]int{},
+		actionColumns:   map[string]int{},
+		reviewColumn:    -1,
+		changedColumn:   -1,
+		agreementColumn: -1,
+	}
+	// The commodity code columns are left out with e.g. "--action describe".
+	if classifies(actions) {
+		for _, territory := range allowedCustomsTerritories {
+			layout.resultColumns[territory] = resultColumn(headings, trOutput("result "+strings.ToUpper(territory)))
+			// The codes of the second model are next to the codes of the first one.
+			if len(comparedModels) > 0 {
+				heading := trOutputf("result %s (%s)", strings.ToUpper(territory), comparedModels[1])
+				layout.modelColumns[territory] = resultColumn(headings, heading)
+			}
+			for _, detail := range details {
+				layout.detailColumns = append(layout.detailColumns, detailColumn{
+					territory: territory,
+					detail:    detail,
+					index:     resultColumn(headings, trOutput(detail.key(territory))),
+				})
+			}
+		}
+	}
+	for _, action := range resultActions(actions) {
+		layout.actionColumns[action] = resultColumn(headings, trOutputf("result %s", actionName(action)))
+	}
+	if slices.ContainsFunc(details, func(detail codeDetail) bool { return detail.include == includeMeasures }) {
+		layout.reviewColumn = resultColumn(headings, trOutput("compliance review"))
+	}
+	if revalidate {
+		layout.changedColumn = resultColumn(headings, trOutput("changed?"))
+	}
+	if len(comparedModels) > 0 {
+		layout.agreementColumn = resultColumn(headings, trOutput("models agree"))
+	}
+	layout.updatedColumn = resultColumn(headings, trOutput("result updated at"))
+	layout.warningColumn = resultColumn(headings, trOutput("warnings"))
+
+	return layout
+}
+
+// classifies reports whether the layout has the commodity code columns.
+func (l resultLayout) classifies() bool {
+	return len(l.resultColumns) > 0
+}
+
+// isResultColumn reports whether the column holds the results, either appended or of an earlier run.
+func (l resultLayout) isResultColumn(column int) bool {
+	if column == l.updatedColumn || column == l.warningColumn || column == l.reviewColumn || column == l.changedColumn ||
+		column == l.agreementColumn || isResultColumn(l.resultColumns, column) || isResultColumn(l.modelColumns, column) ||
+		isResultColumn(l.actionColumns, column) {
+		return true
+	}
+	for _, detailColumn := range l.detailColumns {
+		if column == detailColumn.index {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeDetails writes the code details of the item result to its row.
+func (l resultLayout) writeDetails(row []string, result *ItemResult) {
+	for _, column := range l.detailColumns {
+		if territoryResult, ok := result.Territories[column.territory]; ok {
+			row[column.index] = column.detail.value(territoryResult)
+		}
+	}
+}
+
+// writeReview flags the row of the item for the compliance review with the customs territories whose codes have trade
+// measures, e.g. "EU, NO".
+func (l resultLayout) writeReview(row []string, result *ItemResult) {
+	if l.reviewColumn < 0 {
+		return
+	}
+	var territories []string
+	for _, territory := range allowedCustomsTerritories {
+		if territoryResult, ok := result.Territories[territory]; ok && len(territoryResult.Measures) > 0 {
+			territories = append(territories, strings.ToUpper(territory))
+		}
+	}
+	row[l.reviewColumn] = strings.Join(territories, ", ")
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// The kinds of the code changes between two result files.
+const (
+	codeAdded   = "added"
+	codeRemoved = "removed"
+	codeChanged = "changed"
+)
+
+// codeChange is a difference of the code of an item and a customs territory between two result files.
+type codeChange struct {
+	ID        string `json:"id"`
+	Territory string `json:"customsTerritory"`
+	Change    string `json:"change"` // one of codeAdded, codeRemoved and codeChanged
+	OldCode   string `json:"oldCode,omitempty"`
+	NewCode   string `json:"newCode,omitempty"`
+}
+
+// diffCounts are the numbers of the code changes of a customs territory.
+type diffCounts struct {
+	added, removed, changed, unchanged int
+}
+
+func runDiff(args []string) {
+	fs := newCommandFlagSet("diff")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Compare two result files by the item ID, e.g. of the runs a month apart, and report the added, removed and changed codes
+	of every customs territory.
+
+	Options:
+		--output	write the changes to the file, the format is "xlsx" (default), "csv:" or "json:" and "-" is the standard output.
+				Without the flag, the changes are printed
+		--help		display this help and exit
+
+	Example:
+		customs diff --output csv:changes.csv results/2024-01.xlsx results/2024-02.xlsx
+
+`)
+
+		os.Exit(0)
+	}
+
+	setupQuiet()
+	if fs.NArg() != 2 {
+		fatal("please provide the old and the new result file paths as the command arguments")
+	}
+	for _, output := range outputs {
+		if output.Format != outputFormatXLSX && output.Format != outputFormatCSV && output.Format != outputFormatJSON {
+			fatalf("output format %q is not supported by the diff command, use xlsx, csv or json", output.Format)
+		}
+		if output.Destination == outputStdout && console == os.Stdout {
+			console = os.Stderr
+		}
+	}
+	for _, output := range outputs {
+		if !output.isFile() {
+			continue
+		}
+		if err := confirmOverwrite(output.Destination); err != nil {
+			fatal(err)
+		}
+	}
+
+	var codes [2]map[string]map[string]string
+	var ids [2][]string
+	for i, path := range fs.Args() {
+		file, err := readResultFile(path)
+		if err != nil {
+			fatal(err)
+		}
+		codes[i], ids[i], err = resultCodes(file)
+		if err != nil {
+			fatal(err)
+		}
+	}
+	// The items of the old file are followed by the items added in the new one.
+	for _, id := range ids[1] {
+		if _, ok := codes[0][id]; !ok {
+			ids[0] = append(ids[0], id)
+		}
+	}
+
+	changes, counts := diffCodes(ids[0], codes[0], codes[1])
+	if len(outputs) == 0 {
+		printChanges(changes)
+	}
+	for _, output := range outputs {
+		if err := writeChanges(output, changes); err != nil {
+			fatal(err)
+		}
+	}
+
+	fmt.Fprintln(console, tr("Changes by customs territory:"))
+	for _, territory := range allowedCustomsTerritories {
+		c := counts[territory]
+		fmt.Fprintf(console, "  %s: %s\n", strings.ToUpper(territory), trf("%d added, %d removed, %d changed, %d unchanged",
+			c.added, c.removed, c.changed, c.unchanged))
+	}
+	for _, output := range outputs {
+		fmt.Fprintln(console, trf("The output is written to: %q", output))
+	}
+	printOutputPaths(outputs)
+}
+
+// resultCodes returns the codes of the result file by the item ID and the customs territory, and the IDs of the items in
+// the order of the rows. The cells of the failed items are not codes and are left out.
+func resultCodes(file resultFile) (map[string]map[string]string, []string, error) {
+	idColumn := getColumnIndex(file.headings, "id")
+	if idColumn == nil {
+		return nil, nil, fmt.Errorf("result file %q has no %q column", file.path, "id")
+	}
+	resultColumns, err := file.resultColumns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	codes := map[string]map[string]string{}
+	var ids []string
+	for _, row := range file.rows {
+		id := getCell(row, *idColumn)
+		if id == "" {
+			continue
+		}
+		if _, ok := codes[id]; !ok {
+			codes[id] = map[string]string{}
+			ids = append(ids, id)
+		}
+		for territory, i := range resultColumns {
+			cell := strings.TrimSpace(getCell(row, i))
+			if code := parseResultCell(territory, cell).Code; cell != "" && code != "" {
+				codes[id][territory] = code
+			}
+		}
+	}
+
+	return codes, ids, nil
+}
+
+// diffCodes returns the changes of the codes between the old and the new result files, in the order of the item IDs,
+// and the numbers of the changes by the customs territory.
+func diffCodes(ids []string, old, new map[string]map[string]string) ([]codeChange, map[string]diffCounts) {
+	var changes []codeChange
+	counts := map[string]diffCounts{}
+	for _, id := range ids {
+		for _, territory := range allowedCustomsTerritories {
+			oldCode, newCode := old[id][territory], new[id][territory]
+			c := counts[territory]
+			change := codeChange{ID: id, Territory: territory, OldCode: oldCode, NewCode: newCode}
+			switch {
+			case oldCode == newCode:
+				if oldCode != "" {
+					c.unchanged++
+				}
+			case oldCode == "":
+				change.Change = codeAdded
+				c.added++
+			case newCode == "":
+				change.Change = codeRemoved
+				c.removed++
+			default:
+				change.Change = codeChanged
+				c.changed++
+			}
+			counts[territory] = c
+			if change.Change != "" {
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	return changes, counts
+}
+
+// printChanges prints the changes of the codes, e.g. "A1 EU: 6205100000 → 6205200000".
+func printChanges(changes []codeChange) {
+	for _, change := range changes {
+		territory := strings.ToUpper(change.Territory)
+		switch change.Change {
+		case codeAdded:
+			fmt.Printf("%s %s: %s\n", change.ID, territory, trf("added %s", change.NewCode))
+		case codeRemoved:
+			fmt.Printf("%s %s: %s\n", change.ID, territory, trf("removed %s", change.OldCode))
+		default:
+			fmt.Printf("%s %s: %s → %s\n", change.ID, territory, change.OldCode, change.NewCode)
+		}
+	}
+}
+
+// writeChanges writes the changes of the codes to the output in its format.
+func writeChanges(output OutputConfig, changes []codeChange) error {
+	var buf bytes.Buffer
+	var err error
+	contentType := "application/json"
+	switch output.Format {
+	case outputFormatJSON:
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(struct {
+			Changes []codeChange `json:"changes"`
+		}{Changes: changes})
+	case outputFormatCSV:
+		contentType = "text/csv"
+		err = encodeChangesCSV(&buf, changes)
+	default:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = encodeChangesXLSX(&buf, changes)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeDestination(output, contentType, buf.Bytes())
+}
+
+// changeRows returns the heading and the rows of the changes for the csv and xlsx outputs.
+func changeRows(changes []codeChange) [][]string {
+	rows := [][]string{{"id", trOutput("customs territory"), trOutput("change"), trOutput("old code"), trOutput("new code")}}
+	for _, change := range changes {
+		rows = append(rows, []string{change.ID, strings.ToUpper(change.Territory), trOutput(change.Change), change.OldCode, change.NewCode})
+	}
+
+	return rows
+}
+
+func encodeChangesCSV(w io.Writer, changes []codeChange) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(changeRows(changes)); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+func encodeChangesXLSX(w io.Writer, changes []codeChange) error {
+	file := excelize.NewFile()
+	defer func() {
+		_ = file.Close()
+	}()
+
+	rows := changeRows(changes)
+	for i, row := range rows {
+		// Excel is 1 indexed. The first data row is 2 (the heading is 1).
+		if err := file.SetSheetRow(inputSheet, fmt.Sprintf("A%d", i+1), &row); err != nil {
+			return err
+		}
+	}
+	for i, width := range columnWidths(rows) {
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if err = file.SetColWidth(inputSheet, column, column, width); err != nil {
+			return err
+		}
+	}
+	if err := file.SetPanes(inputSheet, &frozenHeading); err != nil {
+		return err
+	}
+	_, err := file.WriteTo(w)
+
+	return err
+}
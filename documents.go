@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+// maxDocumentSize is the size limit of the documents of the items, e.g. the datasheets. The larger files are most
+// likely not the documents meant for the classification.
+const maxDocumentSize = 20 * 1024 * 1024
+
+// documentPaths returns the paths of the documents in the cell of the documents column. The paths are separated by
+// semicolons or line breaks, the commas are part of the file names. The paths are relative to the directory of the
+// input file, the documents outside of it are rejected like the description files.
+func documentPaths(cell string, source SourceLocation) ([]string, error) {
+	var paths []string
+	for _, path := range strings.FieldsFunc(cell, func(r rune) bool { return r == ';' || r == '\n' }) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		path, err := referencedFilePath(path, source)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("document %q is a directory", path)
+		}
+		if info.Size() > maxDocumentSize {
+			return nil, fmt.Errorf("document %q is larger than %s", path, formatSize(maxDocumentSize))
+		}
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// uploadDocuments uploads the documents of the items and links them to the items by their IDs. The paths are in the
+// order of the items, a document shared by several items is uploaded once.
+func uploadDocuments(ctx context.Context, items []ImportItemRequest, paths [][]string) error {
+	ids := map[string]string{}
+	for _, itemPaths := range paths {
+		for _, path := range itemPaths {
+			ids[path] = ""
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(console, trf("Uploading %d documents of the items.", len(ids)))
+	client := newAPIClient(url, apiKey)
+	for i, itemPaths := range paths {
+		for _, path := range itemPaths {
+			if ids[path] == "" {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				document, err := client.UploadDocument(ctx, filepath.Base(path), content)
+				if errors.Is(err, customs.ErrNotSupported) {
+					return errors.New(tr("the server doesn't accept the documents of the items, remove the documents column"))
+				}
+				if err != nil {
+					return fmt.Errorf("document %q can't be uploaded: %w", path, err)
+				}
+				ids[path] = document.ID
+				logRun(slog.LevelInfo, "document uploaded", "path", path, "id", document.ID)
+			}
+			items[i].Documents = append(items[i].Documents, ids[path])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// durationValue is a flag value holding a time.Duration. Besides the Go duration strings (e.g. "90s" or "10m") it accepts
+// a plain number of seconds, so the scripts written for the integer --timeout flag keep working.
+type durationValue time.Duration
+
+func newDurationValue(value time.Duration, p *time.Duration) *durationValue {
+	*p = value
+	return (*durationValue)(p)
+}
+
+func (d *durationValue) Set(s string) error {
+	if seconds, err := strconv.Atoi(s); err == nil {
+		*d = durationValue(time.Duration(seconds) * time.Second)
+		return nil
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+
+	return nil
+}
+
+func (d *durationValue) String() string {
+	return time.Duration(*d).String()
+}
+
+// UnmarshalJSON reads the duration from a JSON string in the same format as the flag value.
+func (d *durationValue) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	return d.Set(s)
+}
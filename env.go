@@ -0,0 +1,55 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// The environments of the server selected with --env.
+const (
+	envProduction = "prod"
+	envSandbox    = "sandbox"
+)
+
+// environmentURLs are the URLs of the servers of the environments.
+var environmentURLs = map[string]string{
+	envProduction: defaultURL,
+	envSandbox:    "https://sandbox.drotsolutions.com",
+}
+
+// setupEnvironment sets the URL of the environment selected with --env. A URL given with --url must be the URL of the
+// environment, so a run meant for the sandbox is never sent to the production by a leftover flag, or vice versa.
+func setupEnvironment() {
+	if env == "" {
+		return
+	}
+
+	envURL, ok := environmentURLs[env]
+	if !ok {
+		names := make([]string, 0, len(environmentURLs))
+		for name := range environmentURLs {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		fatalf("env flag value %q is not supported, the environments are %s", env, strings.Join(names, ", "))
+	}
+	if isFlagGiven("url") && strings.TrimSuffix(url, "/") != envURL {
+		fatalf("url flag %s is not the URL of the %s environment, please leave it out", url, env)
+	}
+	url = envURL
+}
+
+// environmentOutput returns the default output labelled with the environment, e.g. "result-sandbox.xlsx", so the
+// results of a trial run are not mistaken for the production results. The production results keep the default name.
+func environmentOutput(output string) string {
+	if env == "" || env == envProduction {
+		return output
+	}
+
+	ext := ""
+	if i := strings.LastIndex(output, "."); i >= 0 {
+		output, ext = output[:i], output[i:]
+	}
+
+	return output + "-" + env + ext
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// headingAliases are the other headings of the input columns, by the canonical heading of the column. The common
+// variants of the headings and the localized headings let the files be imported without renaming the columns, e.g.
+// "desc", "Beschreibung" or "beskrivelse" for the description. The generic words (e.g. "title" or "origin") are not
+// aliases, because a file can have such a column for something else, they are mapped with the column aliases of the
+// configuration.
+var headingAliases = map[string][]string{
+	"id":                  {"item id", "item no", "item number", "article number", "product id", "sku", "artikelnummer", "varenummer"},
+	"name":                {"item name", "product name", "navn"},
+	"description":         {"desc", "item description", "product description", "beschreibung", "beskrivelse"},
+	"description file":    {"beschreibungsdatei", "beskrivelsesfil"},
+	"customs territories": {"customs territory", "zollgebiete", "tollområder"},
+	"category":            {"kategorie", "kategori"},
+	"subcategory":         {"unterkategorie", "underkategori"},
+	"country of origin":   {"origin country", "coo", "ursprungsland", "opprinnelsesland"},
+	"gross mass":          {"gross weight", "bruttomasse", "bruttogewicht", "bruttovekt"},
+	"net mass":            {"net weight", "nettomasse", "nettogewicht", "nettovekt"},
+	"weight unit":         {"mass unit", "unit of weight", "gewichtseinheit", "vektenhet"},
+	"model":               {"modell"},
+	"actions":             {"aktionen", "handlinger"},
+	"documents":           {"attachments", "datasheets", "dokumente", "dokumenter"},
+}
+
+// validateColumnAliases checks that the aliases map the input headings to the canonical headings of the columns.
+func validateColumnAliases(aliases map[string]string) error {
+	for heading, name := range aliases {
+		if _, ok := headingAliases[strings.ToLower(name)]; !ok {
+			return fmt.Errorf("column %q of the heading %q is not supported", name, heading)
+		}
+	}
+
+	return nil
+}
+
+// applyColumnAliases adds the column aliases of the configuration and of the profile to the heading aliases. They are
+// matched before the built-in aliases, so a customer's heading can take over a heading variant.
+func applyColumnAliases(config Config, profile string) error {
+	aliases := maps.Clone(config.ColumnAliases)
+	if profile != "" {
+		profileConfig, ok := config.Profiles[profile]
+		if !ok {
+			return fmt.Errorf("profile %q is not in the config file", profile)
+		}
+		if aliases == nil {
+			aliases = map[string]string{}
+		}
+		maps.Copy(aliases, profileConfig.ColumnAliases)
+	}
+	for heading, name := range aliases {
+		name = strings.ToLower(name)
+		headingAliases[name] = append([]string{heading}, headingAliases[name]...)
+	}
+
+	return nil
+}
+
+// setupColumnAliases loads the configuration file for the column aliases, for the commands that read the inputs
+// without sending the imports.
+func setupColumnAliases() {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		fatal(err)
+	}
+	if err = applyColumnAliases(config, profile); err != nil {
+		fatal(err)
+	}
+	registerPlugins(config.Plugins)
+}
+
+// matchesHeading reports whether the heading of the input is the canonical heading of the column or one of its aliases.
+// The headings are compared without the case, the spaces and the punctuation, so "Item_ID" matches "item id".
+func matchesHeading(heading, name string) bool {
+	heading = normalizeName(heading)
+	if heading == normalizeName(name) {
+		return true
+	}
+	for _, alias := range headingAliases[strings.ToLower(name)] {
+		if heading == normalizeName(alias) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeName returns the name, e.g. a heading or a country, in lower case with only its letters and digits.
+func normalizeName(heading string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, heading)
+}
+
+// matchedColumn is an input column matched to an item field.
+type matchedColumn struct {
+	name  string
+	index *int
+}
+
+// reportMatchedColumns prints the input columns whose headings are not the canonical headings of the fields they are
+// read as, so a wrong match of a heading variant is noticed before the import.
+func reportMatchedColumns(headings []string, columns []matchedColumn) {
+	for _, column := range columns {
+		if column.index == nil {
+			continue
+		}
+		heading := strings.TrimSpace(headings[*column.index])
+		if !strings.EqualFold(heading, column.name) {
+			fmt.Fprintln(console, trf("The column %q is read as the %q column.", heading, column.name))
+		}
+	}
+}
+
+// defaultLanguage returns the language of the environment (the LC_ALL, LC_MESSAGES or LANG variable, e.g.
+// "de_DE.UTF-8") if it is supported, otherwise English.
+func defaultLanguage() string {
+	for _, variable := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := strings.ToLower(os.Getenv(variable))
+		if value == "" {
+			continue
+		}
+		language, _, _ := strings.Cut(value, "_")
+		language, _, _ = strings.Cut(language, ".")
+		switch language {
+		case langDE:
+			return langDE
+		// Norwegian Bokmål and Nynorsk have their own codes.
+		case langNO, "nb", "nn":
+			return langNO
+		default:
+			return langEN
+		}
+	}
+
+	return langEN
+}
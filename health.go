@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+// checkHealth checks the state of the server before the import is sent with --health-check, so a long run doesn't
+// fail halfway through an incident. A server that isn't healthy is reported, and with --health-wait the import waits
+// for it to recover, with the backoff of the retry policy between the checks. A server that doesn't report its state
+// is treated as healthy.
+func checkHealth(ctx context.Context) error {
+	if !healthCheck {
+		return nil
+	}
+
+	client := newAPIClient(url, apiKey)
+	deadline := time.Now().Add(healthWait)
+	for attempt := 1; ; attempt++ {
+		health, err := client.GetHealth(ctx)
+		if errors.Is(err, customs.ErrNotSupported) {
+			slog.Debug("the server doesn't report its health")
+			return nil
+		}
+		if err != nil {
+			return trErrorf("the server health can't be checked: %w", err)
+		}
+		if health.Status == customs.HealthStatusOK {
+			if attempt > 1 {
+				fmt.Fprintln(console, tr("The server is healthy again."))
+			}
+			return nil
+		}
+
+		status := health.Status
+		if health.Message != "" {
+			status += " (" + health.Message + ")"
+		}
+		wait := retries.backoff(attempt)
+		if healthWait == 0 {
+			warnf("the server status is %s, the import may fail", status)
+			return nil
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return errors.New(trf("the server status is still %s after %s", status, healthWait))
+		}
+		logRun(slog.LevelWarn, "server not healthy", "status", health.Status, "message", health.Message, "wait", wait)
+		fmt.Fprintln(console, trf("The server status is %s, checking again in %s.", status, wait.Round(time.Second)))
+		if err = sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// runHook runs the command of the hook with the value as JSON on the standard input and decodes its standard output
+// into the result. The command is run by the shell, so it can be a script with arguments or a pipeline. Its standard
+// error is passed through, so the hook can report what it changed.
+func runHook(ctx context.Context, name, command string, value, result any) error {
+	input, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook %q failed: %w", name, command, err)
+	}
+	if err = json.Unmarshal(output.Bytes(), result); err != nil {
+		return fmt.Errorf("%s hook %q returned invalid JSON: %w", name, command, err)
+	}
+
+	return nil
+}
+
+// shellCommand returns the command run by the shell with the arguments, e.g. a script of a hook or a plugin.
+func shellCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", append([]string{"/C", command}, args...)...)
+	}
+
+	// The arguments are passed to the shell as the positional parameters, so they are not parsed by it.
+	return exec.CommandContext(ctx, "sh", append([]string{"-c", command + ` "$@"`, "sh"}, args...)...)
+}
+
+// runPreHook transforms the items with the --pre-hook command before they are sent, e.g. to clean up the
+// descriptions by the customer's rules. The hook can change and leave out the items, but not add new ones, as the
+// results are written to the rows of the input by the item ID.
+func runPreHook(ctx context.Context, imp ImportRequest) (ImportRequest, error) {
+	if preHook == "" {
+		return imp, nil
+	}
+
+	var transformed ImportRequest
+	if err := runHook(ctx, "pre", preHook, imp, &transformed); err != nil {
+		return imp, err
+	}
+	ids := map[string]bool{}
+	for _, item := range imp.ImportItems {
+		ids[item.ID] = true
+	}
+	var unknown []string
+	for _, item := range transformed.ImportItems {
+		if !ids[item.ID] {
+			unknown = append(unknown, item.ID)
+		}
+	}
+	if len(unknown) > 0 {
+		return imp, errors.New(trf("pre hook returned the items that are not in the input: %s", strings.Join(unknown, ", ")))
+	}
+	if len(transformed.ImportItems) == 0 {
+		return imp, errors.New(tr("pre hook left out all items, nothing is imported"))
+	}
+
+	return transformed, nil
+}
+
+// runPostHook transforms the results of the import with the --post-hook command before they are written, e.g. to
+// map the codes to the customer's product groups.
+func runPostHook(ctx context.Context, response *ImportResponse) (*ImportResponse, error) {
+	if postHook == "" || response == nil {
+		return response, nil
+	}
+
+	var transformed ImportResponse
+	if err := runHook(ctx, "post", postHook, response, &transformed); err != nil {
+		return response, err
+	}
+
+	return &transformed, nil
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+// The input types are part of the library, so the readers can be used without the CLI.
+type (
+	InputReader    = customs.InputReader
+	InputItem      = customs.InputItem
+	SourceLocation = customs.SourceLocation
+	ItemError      = customs.ItemError
+)
+
+// inputStdin is the input path of the standard input, e.g. "-" or "json:-".
+const inputStdin = "-"
+
+// InputReaderOpener opens the input at the path, or the standard input for the inputStdin path.
+type InputReaderOpener func(path string) (InputReader, error)
+
+var inputReaders = map[string]InputReaderOpener{}
+
+// inputExtensions maps the file extensions to the input formats.
+var inputExtensions = map[string]string{}
+
+// RegisterInputReader makes the input reader available by the format name and the file extensions. It panics if
+// the format is already registered, so it is meant to be called from the init functions.
+func RegisterInputReader(format string, open InputReaderOpener, extensions ...string) {
+	if _, ok := inputReaders[format]; ok {
+		panic(fmt.Sprintf("input reader %q is already registered", format))
+	}
+	inputReaders[format] = open
+	for _, extension := range extensions {
+		inputExtensions[extension] = format
+	}
+}
+
+// inputFormats returns the names of the registered input readers.
+func inputFormats() []string {
+	formats := make([]string, 0, len(inputReaders))
+	for format := range inputReaders {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// openInput opens the input with the reader for its format. The format is either the prefix of the argument
+// (e.g. "csv:items.txt"), or it is taken from the file extension.
+func openInput(input string) (InputReader, error) {
+	if input == inputStdin {
+		return openStdinInput()
+	}
+	if format, path, ok := strings.Cut(input, ":"); ok {
+		if open, registered := inputReaders[format]; registered {
+			return open(path)
+		}
+	}
+
+	format, ok := inputExtensions[strings.ToLower(filepath.Ext(input))]
+	if !ok {
+		return nil, fmt.Errorf("format of the input %q is not supported, prefix the input with one of the formats: %s", input, strings.Join(inputFormats(), ", "))
+	}
+
+	return inputReaders[format](input)
+}
+
+// inputSnapshot is the state of the input file when its items were read. It guards the results against being written
+// to the wrong columns when the input file is edited during the run.
+type inputSnapshot struct {
+	input    string
+	path     string
+	modTime  time.Time
+	size     int64
+	headings []string
+}
+
+func newInputSnapshot(input string, headings []string) inputSnapshot {
+	snapshot := inputSnapshot{input: input, path: input, headings: slices.Clone(headings)}
+	if format, path, ok := strings.Cut(input, ":"); ok {
+		if _, registered := inputReaders[format]; registered {
+			snapshot.path = path
+		}
+	}
+	// The standard input can't be read again.
+	if snapshot.path == inputStdin {
+		snapshot.path = ""
+	}
+	if info, err := os.Stat(snapshot.path); err == nil {
+		snapshot.modTime = info.ModTime()
+		snapshot.size = info.Size()
+	}
+
+	return snapshot
+}
+
+// verify fails if the headings of the input file are no longer the headings the items were read with. The headings are
+// read again only if the file was modified, the inputs that are not local files are not verified.
+func (s inputSnapshot) verify() error {
+	info, err := os.Stat(s.path)
+	if err != nil || (info.ModTime().Equal(s.modTime) && info.Size() == s.size) {
+		return nil
+	}
+
+	reader, err := openInput(s.input)
+	if err != nil {
+		return fmt.Errorf("input file %q was changed during the run and can't be read, the results are not written: %w", s.path, err)
+	}
+	headings := reader.Headings()
+	_ = reader.Close()
+	// The columns appended after the input columns don't move them, e.g. the result columns of the output written to
+	// the input file.
+	if len(headings) < len(s.headings) || !slices.Equal(headings[:len(s.headings)], s.headings) {
+		return fmt.Errorf("headings of the input file %q were changed during the run, the results are not written to avoid writing them to the wrong columns", s.path)
+	}
+
+	return nil
+}
+
+// guard returns the rows of the input file only if the file is not modified since its items were read, because the
+// results are written to the rows by their position.
+func (s inputSnapshot) guard(source rowSource) rowSource {
+	if source == nil {
+		return nil
+	}
+
+	return func() (func() ([]string, error), func() error, error) {
+		info, err := os.Stat(s.path)
+		if err != nil || !info.ModTime().Equal(s.modTime) || info.Size() != s.size {
+			return nil, nil, fmt.Errorf("input file %q was changed during the run, the results can't be written to its rows", s.path)
+		}
+
+		return source()
+	}
+}
+
+// rowSource opens the rows of an input file again, starting with the headings row. It returns the rows one at a time
+// and the function closing them.
+type rowSource func() (rows func() ([]string, error), close func() error, err error)
+
+// customsTerritoriesSetter is implemented by the readers that can import the items of an input without the customs
+// territories column for other territories than the territories of the run.
+type customsTerritoriesSetter interface {
+	setCustomsTerritories(territories []string)
+}
+
+// tableReader reads the items from the rows of a table, e.g. a sheet or a CSV file. The first row of the table holds
+// the headings.
+type tableReader struct {
+	input    string
+	sheet    string
+	headings []string
+	columns  itemColumns
+	row      int
+	skipped  []int                    // numbers of the rows without an item
+	blankIDs []int                    // numbers of the rows skipped with --on-blank-id skip
+	next     func() ([]string, error) // returns io.EOF after the last row
+	close    func() error
+	reread   rowSource // nil if the input can't be read again, e.g. the standard input
+}
+
+func newTableReader(input, sheet string, next func() ([]string, error), close func() error) (*tableReader, error) {
+	headings, err := next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("provided file is empty or it doesn't have the headings row")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if autoID != "" && getColumnIndex(headings, "id") == nil {
+		headings = append(slices.Clone(headings), autoIDHeading)
+		fmt.Fprintln(console, trf("The input has no id column, the item IDs are generated with --auto-id %s.", autoID))
+	}
+	columns, err := newItemColumns(headings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableReader{
+		input:    input,
+		sheet:    sheet,
+		headings: headings,
+		columns:  columns,
+		row:      1,
+		next:     next,
+		close:    close,
+	}, nil
+}
+
+func (r *tableReader) Headings() []string {
+	return r.headings
+}
+
+func (r *tableReader) Read() (InputItem, error) {
+	values, err := r.next()
+	if err == io.EOF && len(r.skipped) > 0 {
+		warnf("%s: rows %s have no item, e.g. no name and description or the totals, they are not imported", r.input, formatRowNumbers(r.skipped))
+		r.skipped = nil
+	}
+	if err == io.EOF && len(r.blankIDs) > 0 {
+		warnf("%s: rows %s have no item ID, they are not imported", r.input, formatRowNumbers(r.blankIDs))
+		r.blankIDs = nil
+	}
+	if err != nil {
+		return InputItem{}, err
+	}
+	r.row++
+
+	source := SourceLocation{Input: r.input, Sheet: r.sheet, Row: r.row}
+	if !r.columns.holdsItem(values) {
+		r.skipped = append(r.skipped, r.row)
+		return InputItem{Values: values, Source: source, Skipped: true}, nil
+	}
+	if autoID != "" {
+		values = fillItemID(values, r.columns.id, r.row)
+	} else if onBlankID == onBlankIDSkip && !r.columns.hasID(values) {
+		r.blankIDs = append(r.blankIDs, r.row)
+		return InputItem{Values: values, Source: source, Skipped: true}, nil
+	}
+
+	item, err := r.columns.item(values, source)
+	if err != nil {
+		return InputItem{}, &ItemError{Source: source, Err: err}
+	}
+
+	return InputItem{Item: item, Values: values, Source: source}, nil
+}
+
+// rowSource returns the rows of the input read again, so the rows don't have to be held until the outputs are
+// written. It returns nil if the input can't be read again.
+func (r *tableReader) rowSource() rowSource {
+	return r.reread
+}
+
+// setCustomsTerritories sets the customs territories of the items of the input without the customs territories
+// column, instead of the territories of the run.
+func (r *tableReader) setCustomsTerritories(territories []string) {
+	r.columns.territories = territories
+}
+
+func (r *tableReader) Close() error {
+	if r.close == nil {
+		return nil
+	}
+
+	return r.close()
+}
+
+// formatRowNumbers returns the ascending row numbers with the consecutive rows as a range, e.g. "5, 8-12".
+func formatRowNumbers(rows []int) string {
+	var ranges []string
+	for i := 0; i < len(rows); {
+		j := i
+		for j+1 < len(rows) && rows[j+1] == rows[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(rows[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", rows[i], rows[j]))
+		}
+		i = j + 1
+	}
+
+	return strings.Join(ranges, ", ")
+}
+
+// sliceRows returns the function reading the rows one by one from the slice.
+func sliceRows(rows [][]string) func() ([]string, error) {
+	return func() ([]string, error) {
+		if len(rows) == 0 {
+			return nil, io.EOF
+		}
+		row := rows[0]
+		rows = rows[1:]
+
+		return row, nil
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	inputFormatXLSX   = "xlsx"
+	inputFormatCSV    = "csv"
+	inputFormatJSON   = "json"
+	inputFormatSheets = "sheets"
+)
+
+// stdinName is the name of the standard input in the messages.
+const stdinName = "standard input"
+
+// inputSheet is the sheet of the spreadsheet inputs the items are read from.
+const inputSheet = "Sheet1"
+
+func init() {
+	RegisterInputReader(inputFormatXLSX, openXLSXInput, ".xlsx", ".xlsm")
+	RegisterInputReader(inputFormatCSV, openCSVInput, ".csv")
+	RegisterInputReader(inputFormatJSON, openJSONInput, ".json")
+	RegisterInputReader(inputFormatSheets, openSheetsInput)
+}
+
+// xlsxReader reads the items from the spreadsheet. The output is written to the same workbook, so the rest of
+// the spreadsheet is kept in the output.
+type xlsxReader struct {
+	*tableReader
+	file *excelize.File
+}
+
+// Workbook returns the workbook the items are read from.
+func (r *xlsxReader) Workbook() *excelize.File {
+	return r.file
+}
+
+// openXLSXInput opens the spreadsheet, the rows of the input sheet are streamed one at a time, so a large sheet is never
+// held in memory twice.
+func openXLSXInput(path string) (InputReader, error) {
+	var file *excelize.File
+	var err error
+	if path == inputStdin {
+		file, err = excelize.OpenReader(os.Stdin)
+		path = stdinName
+	} else {
+		file, err = excelize.OpenFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := file.Rows(inputSheet)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	closeRows := func() error {
+		if err := rows.Close(); err != nil {
+			_ = file.Close()
+			return err
+		}
+
+		return file.Close()
+	}
+
+	reader, err := newTableReader(path, inputSheet, streamRows(rows), closeRows)
+	if err != nil {
+		_ = closeRows()
+		return nil, err
+	}
+	if path != stdinName {
+		reader.reread = func() (func() ([]string, error), func() error, error) {
+			return rereadXLSXRows(path)
+		}
+	}
+
+	return &xlsxReader{tableReader: reader, file: file}, nil
+}
+
+// rereadXLSXRows opens the input sheet of the spreadsheet again. The workbook the results are written to is not read,
+// its input sheet may already be replaced by the stream writer.
+func rereadXLSXRows(path string) (func() ([]string, error), func() error, error) {
+	file, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := file.Rows(inputSheet)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	return streamRows(rows), func() error {
+		_ = rows.Close()
+		return file.Close()
+	}, nil
+}
+
+// streamRows returns the rows of the spreadsheet iterator one at a time.
+func streamRows(rows *excelize.Rows) func() ([]string, error) {
+	return func() ([]string, error) {
+		if !rows.Next() {
+			if err := rows.Error(); err != nil {
+				return nil, err
+			}
+
+			return nil, io.EOF
+		}
+
+		return rows.Columns()
+	}
+}
+
+// openCSVInput opens the CSV file, the items are read from it one row at a time.
+func openCSVInput(path string) (InputReader, error) {
+	if path == inputStdin {
+		return newCSVReader(stdinName, os.Stdin, nil)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := newCSVReader(path, file, file.Close)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	reader.reread = func() (func() ([]string, error), func() error, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return newCSVRows(file), file.Close, nil
+	}
+
+	return reader, nil
+}
+
+func newCSVReader(input string, r io.Reader, close func() error) (*tableReader, error) {
+	return newTableReader(input, "", newCSVRows(r), close)
+}
+
+// newCSVRows returns the rows of the CSV file one at a time.
+func newCSVRows(r io.Reader) func() ([]string, error) {
+	cr := csv.NewReader(r)
+	// The rows don't need to have the same number of values as the headings, like in the spreadsheets.
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	return cr.Read
+}
+
+// openJSONInput opens the JSON file with an array of objects, the keys of the objects are the column names, e.g.
+// [{"id": "1", "name": "Shirt", "description": "Cotton shirt", "customs territories": ["eu", "no"]}].
+func openJSONInput(path string) (InputReader, error) {
+	if path == inputStdin {
+		return newJSONReader(stdinName, os.Stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	reader, err := newJSONReader(path, file)
+	if err != nil {
+		return nil, err
+	}
+	reader.reread = func() (func() ([]string, error), func() error, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		rows, err := readJSONRows(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON input %q: %w", path, err)
+		}
+
+		return sliceRows(rows), func() error { return nil }, nil
+	}
+
+	return reader, nil
+}
+
+func newJSONReader(input string, r io.Reader) (*tableReader, error) {
+	rows, err := readJSONRows(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON input %q: %w", input, err)
+	}
+
+	return newTableReader(input, "", sliceRows(rows), nil)
+}
+
+// openStdinInput reads the CSV or JSON items from the standard input, the format is told by the first character.
+// The other formats are read from the standard input with the format prefix, e.g. "xlsx:-".
+func openStdinInput() (InputReader, error) {
+	br := bufio.NewReader(os.Stdin)
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil, fmt.Errorf("provided file is empty or it doesn't have the headings row")
+		}
+		switch {
+		case b[0] == '[':
+			return newJSONReader(stdinName, br)
+		case unicode.IsSpace(rune(b[0])):
+			_, _ = br.Discard(1)
+		default:
+			return newCSVReader(stdinName, br, nil)
+		}
+	}
+}
+
+// readJSONRows reads the array of objects as the table rows. The headings are the keys of the objects in the order of
+// their first appearance.
+func readJSONRows(r io.Reader) ([][]string, error) {
+	var objects []map[string]any
+	var keys []string
+	seen := map[string]bool{}
+
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('[') {
+		return nil, fmt.Errorf("expected an array of objects")
+	}
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if token != json.Delim('{') {
+			return nil, fmt.Errorf("expected an array of objects")
+		}
+
+		object := map[string]any{}
+		for decoder.More() {
+			token, err = decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := token.(string)
+			var value any
+			err = decoder.Decode(&value)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = value
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		// Closing brace of the object.
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+
+	rows := [][]string{keys}
+	for _, object := range objects {
+		row := make([]string, len(keys))
+		for i, key := range keys {
+			row[i] = jsonValueToString(object[key])
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// jsonValueToString formats the JSON value as the spreadsheet cell, arrays are joined with commas.
+func jsonValueToString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = jsonValueToString(item)
+		}
+		return strings.Join(values, ",")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// openSheetsInput reads the first sheet of a Google Sheets spreadsheet, given by its URL or ID. The spreadsheet is
+// downloaded as CSV, so it has to be shared with anyone with the link.
+func openSheetsInput(spreadsheet string) (InputReader, error) {
+	id := spreadsheet
+	if isURL(spreadsheet) {
+		// e.g. https://docs.google.com/spreadsheets/d/<id>/edit#gid=0
+		_, rest, ok := strings.Cut(spreadsheet, "/spreadsheets/d/")
+		if !ok {
+			return nil, fmt.Errorf("invalid Google Sheets URL %q", spreadsheet)
+		}
+		id, _, _ = strings.Cut(rest, "/")
+	}
+
+	res, err := httpClient.Get(fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/export?format=csv", id))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("unexpected status code while downloading the spreadsheet %q %d", spreadsheet, res.StatusCode)
+	}
+
+	reader, err := newCSVReader(spreadsheet, res.Body, res.Body.Close)
+	if err != nil {
+		_ = res.Body.Close()
+		return nil, err
+	}
+
+	return reader, nil
+}
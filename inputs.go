@@ -0,0 +1,329 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// inputPlaceholder is replaced in the output destinations by the name of the input, so every input of a run is
+// written to its own outputs, e.g. "results/{input}.xlsx".
+const inputPlaceholder = "{input}"
+
+const (
+	// doneDir and failedDir are the subdirectories of the input directory the imported and the failed inputs are moved to.
+	doneDir   = "done"
+	failedDir = "failed"
+)
+
+// inputSummary is the outcome of an input of the run, printed in the summary of the runs with several inputs.
+type inputSummary struct {
+	input    string
+	items    int
+	warnings int
+	changed  int // codes changed since the earlier run, with --revalidate
+	err      error
+}
+
+// importOptions are the options of the import of one input that differ between the commands.
+type importOptions struct {
+	several bool // the input is one of several inputs of the run
+	// job is set for the imports of the serve jobs: the upload confirms the import, and an interrupt stops the server,
+	// so the import fails instead of exiting with the partial results.
+	job bool
+	// anonymous holds the tokens of the item IDs of the input with --anonymize, it is set by importInput.
+	anonymous *anonymizedIDs
+	// sent holds the locations of the imports of the input sent by an earlier attempt, e.g. of a watched file. They are
+	// awaited instead of being sent again, and the locations of this attempt are added. Without it only the imports of
+	// the --resume flag are awaited.
+	sent *sentImports
+}
+
+// expandInputs returns the inputs of the arguments, with the glob patterns (e.g. "exports/*.xlsx") replaced by the
+// matching files. The format prefix of an argument is kept for all its files, e.g. "csv:exports/*.txt".
+func expandInputs(args []string) ([]string, error) {
+	var inputs []string
+	for _, arg := range args {
+		prefix, path := "", arg
+		if format, p, ok := strings.Cut(arg, ":"); ok {
+			if _, registered := inputReaders[format]; registered {
+				prefix, path = format+":", p
+			}
+		}
+		if isURL(path) || !strings.ContainsAny(path, "*?[") {
+			if !slices.Contains(inputs, arg) {
+				inputs = append(inputs, arg)
+			}
+			continue
+		}
+
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no input files match the pattern %q", arg)
+		}
+		for _, match := range matches {
+			if !slices.Contains(inputs, prefix+match) {
+				inputs = append(inputs, prefix+match)
+			}
+		}
+	}
+
+	return inputs, nil
+}
+
+// listInputDir returns the files of the directory in one of the supported input formats. The subdirectories, including
+// the done and failed directories, are not read.
+func listInputDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("input directory can't be read: %w", err)
+	}
+
+	var inputs []string
+	for _, entry := range entries {
+		// Hidden files are skipped, e.g. the lock files of the spreadsheet editors or the files being copied.
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") || strings.HasPrefix(entry.Name(), "~$") {
+			continue
+		}
+		if _, ok := inputExtensions[strings.ToLower(filepath.Ext(entry.Name()))]; ok {
+			inputs = append(inputs, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return inputs, nil
+}
+
+// moveInput moves the input from the input directory to the done subdirectory, or to the failed subdirectory if it
+// failed. A file of the same name from an earlier run is kept, the input gets the time of the run in its name.
+func moveInput(input string, done bool) error {
+	dir := filepath.Join(filepath.Dir(input), failedDir)
+	if done {
+		dir = filepath.Join(filepath.Dir(input), doneDir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	destination := filepath.Join(dir, filepath.Base(input))
+	if _, err := os.Stat(destination); err == nil {
+		extension := filepath.Ext(input)
+		destination = filepath.Join(dir, inputName(input)+"-"+runStartedAt.Format("20060102-150405")+extension)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.Rename(input, destination)
+}
+
+// inputName returns the file name of the input without the format prefix and the extension, e.g. "items" for
+// "csv:exports/items.txt".
+func inputName(input string) string {
+	if format, path, ok := strings.Cut(input, ":"); ok {
+		if _, registered := inputReaders[format]; registered {
+			input = path
+		}
+	}
+	name := filepath.Base(input)
+
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// inputOutputs returns the outputs of the input, with the input placeholder replaced by the input name.
+func inputOutputs(outputs []OutputConfig, input string) []OutputConfig {
+	resolved := make([]OutputConfig, len(outputs))
+	for i, output := range outputs {
+		output.Destination = strings.ReplaceAll(output.Destination, inputPlaceholder, inputName(input))
+		resolved[i] = output
+	}
+	if inPlace {
+		if output, err := inPlaceOutput(input); err == nil {
+			resolved = append(resolved, output)
+		}
+	}
+
+	return resolved
+}
+
+// inPlaceOutput returns the output of the --in-place flag, which writes the results to the input file itself in the
+// format of the input.
+func inPlaceOutput(input string) (OutputConfig, error) {
+	format, path := "", input
+	if f, p, ok := strings.Cut(input, ":"); ok {
+		if _, registered := inputReaders[f]; registered {
+			format, path = f, p
+		}
+	}
+	if format == "" {
+		format = inputExtensions[strings.ToLower(filepath.Ext(path))]
+	}
+
+	output := OutputConfig{Format: format, Destination: path}
+	if _, ok := outputWriters[format]; !ok || !output.isFile() {
+		return OutputConfig{}, fmt.Errorf("input %q can't be updated in place, only the xlsx, csv and json files can", input)
+	}
+
+	return output, nil
+}
+
+// isInPlaceOutput reports whether the output writes to the input file updated in place.
+func isInPlaceOutput(output OutputConfig, input string) bool {
+	if !inPlace {
+		return false
+	}
+	inputOutput, err := inPlaceOutput(input)
+
+	return err == nil && output.Destination == inputOutput.Destination
+}
+
+// inputLast moves the outputs replacing the input file, e.g. the output of --in-place, after the other outputs, because
+// the rows of the input file are read again for every output.
+func inputLast(outputs []OutputConfig, path string) []OutputConfig {
+	replacesInput := func(output OutputConfig) bool {
+		return path != "" && output.isFile() && filepath.Clean(output.Destination) == filepath.Clean(path)
+	}
+	ordered := slices.Clone(outputs)
+	slices.SortStableFunc(ordered, func(a, b OutputConfig) int {
+		switch {
+		case !replacesInput(a) && replacesInput(b):
+			return -1
+		case replacesInput(a) && !replacesInput(b):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return ordered
+}
+
+// backupInput copies the input file before it is updated in place, the copy gets the time of the run in its name, e.g.
+// "items.backup-20240131-150405.xlsx". It returns the path of the copy.
+func backupInput(path string) (string, error) {
+	extension := filepath.Ext(path)
+	backup := strings.TrimSuffix(path, extension) + ".backup-" + runStartedAt.Format("20060102-150405") + extension
+
+	source, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+	info, err := source.Stat()
+	if err != nil {
+		return "", err
+	}
+	// An existing backup is never overwritten.
+	destination, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return "", fmt.Errorf("backup of the input %q can't be created: %w", path, err)
+	}
+	if _, err = io.Copy(destination, source); err != nil {
+		_ = destination.Close()
+		return "", err
+	}
+
+	return backup, destination.Close()
+}
+
+// inOutputDir returns the outputs with the relative file destinations in the output directory.
+func inOutputDir(outputs []OutputConfig, dir string) []OutputConfig {
+	resolved := make([]OutputConfig, len(outputs))
+	for i, output := range outputs {
+		if output.isFile() && !filepath.IsAbs(output.Destination) {
+			output.Destination = filepath.Join(dir, output.Destination)
+		}
+		resolved[i] = output
+	}
+
+	return resolved
+}
+
+// validateInputDirOutputs fails if an output file is written to the input directory, where it would be imported by
+// the next run.
+func validateInputDirOutputs(outputs []OutputConfig, dir string) error {
+	inputDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	for _, output := range outputs {
+		if !output.isFile() {
+			continue
+		}
+		outputDir, err := filepath.Abs(filepath.Dir(output.Destination))
+		if err != nil {
+			return err
+		}
+		if outputDir == inputDir {
+			return fmt.Errorf("output %q is in the input directory and would be imported by the next run, write it to another directory with --output-dir", output.Destination)
+		}
+	}
+
+	return nil
+}
+
+// validateInputOutputs fails if the inputs would write to the same output file, so the results of an input are not
+// overwritten by the next one.
+func validateInputOutputs(outputs []OutputConfig, inputs []string) error {
+	if len(inputs) > 1 {
+		if err := requireInputPlaceholder(outputs); err != nil {
+			return err
+		}
+	}
+
+	written := map[string]string{}
+	for _, input := range inputs {
+		for _, output := range inputOutputs(outputs, input) {
+			if !output.isFile() {
+				continue
+			}
+			if other, ok := written[output.Destination]; ok {
+				return fmt.Errorf("inputs %q and %q are written to the same output %q", other, input, output.Destination)
+			}
+			written[output.Destination] = input
+		}
+	}
+
+	return nil
+}
+
+// requireInputPlaceholder fails if an output file has no input placeholder in its name, so it would be overwritten by
+// every input.
+func requireInputPlaceholder(outputs []OutputConfig) error {
+	for _, output := range outputs {
+		if output.isFile() && !strings.Contains(output.Destination, inputPlaceholder) {
+			return fmt.Errorf("output %q is written for every input, add %s to its name, e.g. --output %q", output.Destination, inputPlaceholder, inputPlaceholder+"-"+defaultOutput)
+		}
+	}
+
+	return nil
+}
+
+// printSummary prints the outcome of every input of the run.
+func printSummary(summaries []inputSummary) {
+	fmt.Fprintf(console, "\n%s\n", tr("Summary:"))
+	items, warnings := 0, 0
+	for _, summary := range summaries {
+		if summary.err != nil {
+			fmt.Fprintf(console, "  %s\n", colorize(console, colorRed, trf("%s: failed: %s", summary.input, summary.err)))
+			continue
+		}
+		line := trf("%s: %d items, %d with warnings", summary.input, summary.items, summary.warnings)
+		if revalidate {
+			line += trf(", %d changed codes", summary.changed)
+		}
+		color := colorGreen
+		if summary.warnings > 0 {
+			color = colorYellow
+		}
+		fmt.Fprintf(console, "  %s\n", colorize(console, color, line))
+		items += summary.items
+		warnings += summary.warnings
+	}
+	fmt.Fprintln(console, trf("%d inputs, %d items, %d with warnings", len(summaries), items, warnings))
+}
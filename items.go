@@ -0,0 +1,440 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxDescriptionFileSize is the size limit of the description files, it keeps the import requests in the size the
+// server accepts.
+const maxDescriptionFileSize = 1024 * 1024
+
+// itemColumns holds the indexes of the input columns the item fields are read from. The optional columns are nil if
+// they are missing in the input.
+type itemColumns struct {
+	id                 int
+	name               int
+	description        *int // is optional if the description is read from the description file
+	customsTerritories *int // is optional if the default customs territories are set
+
+	descriptionFile *int
+	category        *int
+	subcategory     *int
+	countryOfOrigin *int
+	grossMass       *int
+	netMass         *int
+	weightUnit      *int
+	model           *int
+	actions         *int // actions of the item instead of the requested actions of the run, e.g. "classify, describe"
+	documents       *int // paths of the documents uploaded for the item, e.g. "datasheet.pdf; specification.pdf"
+
+	attributes map[int]string // headings of the other columns by the index, with --attributes
+	// territories are the customs territories of the items without the customs territories column, e.g. of a serve
+	// job. The defaultCustomsTerritories of the run are used if nil.
+	territories []string
+
+	// headings and matched are the columns the description is composed of with --description-template.
+	headings []string
+	matched  []matchedColumn
+}
+
+func newItemColumns(headings []string) (itemColumns, error) {
+	var columns itemColumns
+	if strictColumns {
+		if err := checkUnknownColumns(headings); err != nil {
+			return columns, err
+		}
+	}
+	var err error
+	columns.id, err = getMandatoryColumnIndex(headings, "id")
+	if err != nil {
+		return columns, err
+	}
+	columns.name, err = getMandatoryColumnIndex(headings, "name")
+	if err != nil {
+		return columns, err
+	}
+	columns.description = getColumnIndex(headings, "description")
+	columns.descriptionFile = getColumnIndex(headings, "description file")
+	if columns.description == nil && columns.descriptionFile == nil && descriptionTmpl == nil {
+		return columns, errors.New(trf(`provided file has no %q column`, "description"))
+	}
+	columns.customsTerritories = getColumnIndex(headings, "customs territories")
+
+	columns.category = getColumnIndex(headings, "category")
+	columns.subcategory = getColumnIndex(headings, "subcategory")
+	columns.countryOfOrigin = getColumnIndex(headings, "country of origin")
+	columns.grossMass = getColumnIndex(headings, "gross mass")
+	columns.netMass = getColumnIndex(headings, "net mass")
+	columns.weightUnit = getColumnIndex(headings, "weight unit")
+	columns.model = getColumnIndex(headings, "model")
+	columns.actions = getColumnIndex(headings, "actions")
+	columns.documents = getColumnIndex(headings, "documents")
+
+	matched := []matchedColumn{
+		{"id", &columns.id},
+		{"name", &columns.name},
+		{"description", columns.description},
+		{"description file", columns.descriptionFile},
+		{"customs territories", columns.customsTerritories},
+		{"category", columns.category},
+		{"subcategory", columns.subcategory},
+		{"country of origin", columns.countryOfOrigin},
+		{"gross mass", columns.grossMass},
+		{"net mass", columns.netMass},
+		{"weight unit", columns.weightUnit},
+		{"model", columns.model},
+		{"actions", columns.actions},
+		{"documents", columns.documents},
+	}
+	reportMatchedColumns(headings, matched)
+	if attributes {
+		columns.attributes = attributeColumns(headings, matched)
+	}
+	if descriptionTmpl != nil {
+		columns.headings, columns.matched = headings, matched
+	}
+
+	return columns, nil
+}
+
+// totalsLabels are the labels of the totals rows below the items, as normalized names.
+var totalsLabels = []string{
+	"total", "totals", "subtotal", "grandtotal", "sum", "summe", "zwischensumme", "gesamt", "gesamtsumme", "totalt",
+	"delsum",
+}
+
+// holdsItem reports whether the row has the name or the description of an item and is not a totals row. The other
+// rows, e.g. the blank rows or a totals row below the items, are not imported.
+func (c itemColumns) holdsItem(row []string) bool {
+	if c.isTotals(row) {
+		return false
+	}
+	for _, i := range []*int{&c.name, c.description, c.descriptionFile} {
+		if strings.TrimSpace(getString(row, i)) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTotals reports whether the row is a totals row: its ID, name or description is a totals label, e.g. "Total:" or
+// "Summe", or all its values are numbers.
+func (c itemColumns) isTotals(row []string) bool {
+	for _, i := range []*int{&c.id, &c.name, c.description} {
+		if slices.Contains(totalsLabels, normalizeName(getString(row, i))) {
+			return true
+		}
+	}
+
+	numbers := 0
+	for _, value := range row {
+		value = strings.NewReplacer(" ", "", ",", "").Replace(value)
+		if value == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return false
+		}
+		numbers++
+	}
+
+	return numbers > 0
+}
+
+// hasID reports whether the row has the item ID.
+func (c itemColumns) hasID(row []string) bool {
+	return strings.TrimSpace(getString(row, &c.id)) != ""
+}
+
+// item maps the input row to the import item.
+func (c itemColumns) item(row []string, source SourceLocation) (ImportItemRequest, error) {
+	var err error
+	id := getString(row, &c.id)
+	if strings.TrimSpace(id) == "" {
+		return ImportItemRequest{}, errors.New(tr("item has no ID, use --auto-id to generate the missing IDs or --on-blank-id skip to skip the rows"))
+	}
+	name := getString(row, &c.name)
+	description := getString(row, c.description)
+	if descriptionFile := getString(row, c.descriptionFile); descriptionFile != "" {
+		description, err = readDescriptionFile(descriptionFile, source)
+		if err != nil {
+			return ImportItemRequest{}, fmt.Errorf("invalid description file for item %q: %w", id, err)
+		}
+	}
+	if descriptionTmpl != nil {
+		description, err = executeDescriptionTemplate(descriptionTmpl, templateValues(c.headings, row, c.matched))
+		if err != nil {
+			return ImportItemRequest{}, err
+		}
+	}
+	customsTerritories := c.territories
+	if customsTerritories == nil {
+		customsTerritories = defaultCustomsTerritories
+	}
+	if c.customsTerritories != nil {
+		customsTerritories, err = prepareCustomsTerritories(getString(row, c.customsTerritories))
+		if err != nil {
+			return ImportItemRequest{}, err
+		}
+	}
+	if len(customsTerritories) == 0 {
+		return ImportItemRequest{}, errors.New(trf(`provided file has no %q column`, "customs territories"))
+	}
+
+	category := getStringPtr(row, c.category)
+	subcategory := getStringPtr(row, c.subcategory)
+	countryOfOrigin := normalizeCountry(getStringPtr(row, c.countryOfOrigin))
+	if countryOfOrigin != nil && *countryOfOrigin != "" {
+		// The server rejects the unknown countries, they are reported for all rows before anything is sent.
+		if _, ok := countryCode(*countryOfOrigin); !ok {
+			if err = handleInvalidOptional("country of origin", id, *countryOfOrigin, source); err != nil {
+				return ImportItemRequest{}, err
+			}
+			countryOfOrigin = nil
+		}
+	}
+	grossMass, err := getFloatPtr(row, c.grossMass)
+	if err != nil {
+		if err = handleInvalidOptional("gross mass", id, getString(row, c.grossMass), source); err != nil {
+			return ImportItemRequest{}, err
+		}
+	}
+	netMass, err := getFloatPtr(row, c.netMass)
+	if err != nil {
+		if err = handleInvalidOptional("net mass", id, getString(row, c.netMass), source); err != nil {
+			return ImportItemRequest{}, err
+		}
+	}
+	weightUnit := getStringPtr(row, c.weightUnit)
+	language := detectItemLanguage(name, description, id, source)
+	model := getStringPtr(row, c.model)
+	actionNames := requestedActions
+	if names := parseActionList(getString(row, c.actions)); len(names) > 0 {
+		actionNames = names
+	}
+	actions := make([]ActionRequest, len(actionNames))
+	for i, action := range actionNames {
+		actions[i] = ActionRequest{
+			Name:       action,
+			Parameters: Parameters{CustomsTerritories: customsTerritories, Model: model},
+		}
+		if action == actionDetermineCommodityCodes {
+			actions[i].Parameters.Include = includedDetails()
+		}
+	}
+
+	var itemAttributes map[string]string
+	for i, heading := range c.attributes {
+		if value := strings.TrimSpace(getCell(row, i)); value != "" {
+			if itemAttributes == nil {
+				itemAttributes = map[string]string{}
+			}
+			itemAttributes[heading] = value
+		}
+	}
+
+	return ImportItemRequest{
+		ID:              id,
+		Name:            name,
+		Description:     description,
+		Category:        category,
+		Subcategory:     subcategory,
+		CountryOfOrigin: countryOfOrigin,
+		GrossMass:       grossMass,
+		NetMass:         netMass,
+		WeightUnit:      weightUnit,
+		Language:        language,
+		Actions:         actions,
+		Attributes:      itemAttributes,
+	}, nil
+}
+
+// readDescriptionFile reads the description from the text file, for the descriptions longer than a spreadsheet cell
+// can hold. The path is relative to the directory of the input file.
+func readDescriptionFile(path string, source SourceLocation) (string, error) {
+	path, err := referencedFilePath(path, source)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxDescriptionFileSize {
+		return "", fmt.Errorf("file %q is larger than %d bytes", path, maxDescriptionFileSize)
+	}
+
+	description, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(description)), nil
+}
+
+// referencedFilePath returns the path of a file a cell of the input refers to, e.g. a description file. The path is
+// relative to the directory of the input file, or to the working directory for the standard input and the URLs, and
+// has to stay inside it, also through the symbolic links. Otherwise a spreadsheet from anyone could make the tool read
+// any file of the machine and send it to the server.
+func referencedFilePath(path string, source SourceLocation) (string, error) {
+	root := "."
+	input := source.Input
+	if format, formatPath, ok := strings.Cut(input, ":"); ok {
+		if _, registered := inputReaders[format]; registered {
+			input = formatPath
+		}
+	}
+	if input != inputStdin && !isURL(input) {
+		root = filepath.Dir(input)
+	}
+
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", fmt.Errorf("file %q must be relative to the directory of the input", path)
+	}
+	joined := filepath.Join(root, path)
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(joined)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(resolvedRoot, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file %q is outside of the directory of the input", path)
+	}
+
+	return joined, nil
+}
+
+// handleInvalidOptional applies the --on-invalid policy to an invalid value of an optional column. With the drop policy
+// the value is left out of the import with a warning, so a single bad cell doesn't stop the whole import.
+func handleInvalidOptional(column, itemID, value string, source SourceLocation) error {
+	if onInvalid == onInvalidDrop {
+		warnf("%s: invalid %s %q for item %q is dropped", source, column, value, itemID)
+		return nil
+	}
+
+	return errors.New(trf("invalid %s %q for item %q", column, value, itemID))
+}
+
+// handleDuplicateID applies the --on-duplicate policy to the item whose ID is already used by an earlier row, so the
+// results are not written to the wrong row. The suffixed ID is also written to the input values of the item, so the
+// results are written to its row. It reports whether the item is left out of the import.
+func handleDuplicateID(item *InputItem, idColumn *int, ids map[string]bool) (bool, error) {
+	id := item.Item.ID
+	if !ids[id] {
+		ids[id] = true
+		return false, nil
+	}
+
+	switch onDuplicate {
+	case onDuplicateFirst:
+		// The row is reported when the results are written.
+		return true, nil
+	case onDuplicateSuffix:
+		suffixed := id
+		for n := 2; ids[suffixed]; n++ {
+			suffixed = fmt.Sprintf("%s-%d", id, n)
+		}
+		ids[suffixed] = true
+		item.Item.ID = suffixed
+		if idColumn != nil && *idColumn < len(item.Values) {
+			item.Values[*idColumn] = suffixed
+		}
+		warnf("%s: duplicate item ID %q is imported as %q", item.Source, id, suffixed)
+		return false, nil
+	default:
+		return false, errors.New(trf("%s: item ID %q is already used by an earlier row, use --on-duplicate first or suffix to import the file", item.Source, id))
+	}
+}
+
+func getMandatoryColumnIndex(row []string, name string) (int, error) {
+	index := getColumnIndex(row, name)
+	if index == nil {
+		return 0, errors.New(trf(`provided file has no %q column`, name))
+	}
+
+	return *index, nil
+}
+
+// getColumnIndex returns the index of the column with the heading, or with one of its aliases if the heading is missing.
+func getColumnIndex(row []string, name string) *int {
+	for i, rowName := range row {
+		if normalizeName(rowName) == normalizeName(name) {
+			return &i
+		}
+	}
+	for i, rowName := range row {
+		if matchesHeading(rowName, name) {
+			return &i
+		}
+	}
+
+	return nil
+}
+
+// getString returns the value of the column, or an empty string if the column is missing or the row is shorter.
+func getString(row []string, i *int) string {
+	if i == nil {
+		return ""
+	}
+
+	return getCell(row, *i)
+}
+
+// getCell returns the value of the column, or an empty string if the row is shorter.
+func getCell(row []string, i int) string {
+	if i >= len(row) {
+		return ""
+	}
+
+	return row[i]
+}
+
+// getStringPtr returns the value of the column, or nil if the column is missing or the row is shorter.
+func getStringPtr(row []string, i *int) *string {
+	if i == nil || *i >= len(row) {
+		return nil
+	}
+
+	return &row[*i]
+}
+
+func getFloatPtr(row []string, i *int) (*float64, error) {
+	if i == nil {
+		return nil, nil
+	}
+	value := getString(row, i)
+	if value == "" {
+		return nil, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, err
+}
+
+func prepareCustomsTerritories(customsTerritories string) ([]string, error) {
+	var result []string
+	for _, territory := range strings.Split(customsTerritories, ",") {
+		territory = strings.TrimSpace(strings.ToLower(territory))
+		if !slices.Contains(allowedCustomsTerritories, territory) {
+			return nil, fmt.Errorf("customs territory %q is not supported", territory)
+		}
+		result = append(result, territory)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageMarkers are the common words of the languages the items are detected in. The detection is meant only as
+// a hint for the server, so a short list of the most frequent words is enough.
+var languageMarkers = map[string][]string{
+	langEN: {"the", "and", "with", "for", "of", "in", "to", "from", "made", "without", "or", "is"},
+	langDE: {"und", "mit", "für", "aus", "der", "die", "das", "ohne", "oder", "ist", "ein", "eine", "von", "zum", "zur"},
+	langNO: {"og", "med", "for", "av", "til", "fra", "uten", "eller", "er", "en", "et", "som", "på", "laget"},
+}
+
+// languageLetters are the letters that only appear in one of the detected languages.
+var languageLetters = map[string]string{
+	langDE: "äöüß",
+	langNO: "æøå",
+}
+
+// detectLanguage returns the language of the text, or an empty string if it can't be told, e.g. for a short name
+// without any common words.
+func detectLanguage(text string) string {
+	scores := map[string]int{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		for language, markers := range languageMarkers {
+			for _, marker := range markers {
+				if word == marker {
+					scores[language]++
+				}
+			}
+		}
+		for language, letters := range languageLetters {
+			if strings.ContainsAny(word, letters) {
+				scores[language] += 2
+			}
+		}
+	}
+
+	// The language has to clearly lead, otherwise the text is treated as undetected.
+	best, bestScore, secondScore := "", 0, 0
+	for _, language := range allowedLanguages {
+		score := scores[language]
+		if score > bestScore {
+			best, bestScore, secondScore = language, score, bestScore
+		} else if score > secondScore {
+			secondScore = score
+		}
+	}
+	if bestScore < 2 || bestScore == secondScore {
+		return ""
+	}
+
+	return best
+}
+
+// detectItemLanguage returns the language of the item description, or of the name if the description language is not
+// detected. Items whose name and description are detected in different languages are reported, because the server
+// classifies them less reliably.
+func detectItemLanguage(name, description, itemID string, source SourceLocation) *string {
+	nameLanguage := detectLanguage(name)
+	descriptionLanguage := detectLanguage(description)
+	if nameLanguage != "" && descriptionLanguage != "" && nameLanguage != descriptionLanguage {
+		warnf("%s: name of the item %q is in %s and the description in %s", source, itemID, nameLanguage, descriptionLanguage)
+	}
+
+	language := descriptionLanguage
+	if language == "" {
+		language = nameLanguage
+	}
+	if language == "" {
+		return nil
+	}
+
+	return &language
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// minColumnWidth and maxColumnWidth are the limits of the fitted column widths, in characters. The longer values,
+	// e.g. the descriptions and the error messages, are cut off in the view.
+	minColumnWidth = 8
+	maxColumnWidth = 60
+)
+
+const (
+	filterDatabaseName = "_xlnm._FilterDatabase"
+	criteriaName       = "_xlnm.Criteria"
+)
+
+// frozenHeading freezes the heading row of the sheet, so it stays visible while the rows are scrolled.
+var frozenHeading = excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}
+
+// columnWidths returns the widths of the columns fitting their longest values.
+func columnWidths(rows [][]string) []float64 {
+	var widths []float64
+	for _, row := range rows {
+		widths = fitColumnWidths(widths, row)
+	}
+
+	return widths
+}
+
+// fitColumnWidths widens the columns to fit the values of the row, so the widths are fitted one row at a time.
+func fitColumnWidths(widths []float64, row []string) []float64 {
+	for len(widths) < len(row) {
+		widths = append(widths, minColumnWidth)
+	}
+	for i, value := range row {
+		// The padding keeps the value clear of the auto-filter button.
+		width := float64(utf8.RuneCountInString(value) + 2)
+		if width > widths[i] {
+			widths[i] = min(width, maxColumnWidth)
+		}
+	}
+
+	return widths
+}
+
+// formatSheet freezes the heading row of the input sheet, adds the auto-filter to the headings and fits the widths of
+// the columns to the widths fitting the rows, so the reviewers of the results don't have to reformat the output. Of the
+// input workbook, only the widths of the appended result columns are fitted, and the headings are frozen only if the
+// sheet has no panes yet.
+func (d *ResultDocument) formatSheet(widths []float64, rows int) error {
+	for i, width := range widths {
+		if d.inputWorkbook && i < d.inputColumns {
+			continue
+		}
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if err = d.file.SetColWidth(inputSheet, column, column, width); err != nil {
+			return err
+		}
+	}
+
+	panes, err := d.file.GetPanes(inputSheet)
+	if err != nil {
+		return err
+	}
+	if !panes.Freeze && panes.XSplit == 0 && panes.YSplit == 0 {
+		if err = d.file.SetPanes(inputSheet, &frozenHeading); err != nil {
+			return err
+		}
+	}
+
+	return d.setAutoFilter(len(widths), rows)
+}
+
+// setAutoFilter adds the auto-filter to the heading row of the input sheet, unless the sheet has a table, which has
+// its own filter.
+func (d *ResultDocument) setAutoFilter(columns, rows int) error {
+	tables, err := d.file.GetTables(inputSheet)
+	if err != nil || len(tables) > 0 || columns == 0 {
+		return err
+	}
+	lastCell, err := excelize.CoordinatesToCellName(columns, rows)
+	if err != nil {
+		return err
+	}
+
+	// Adding the auto-filter replaces the sheet properties, e.g. the tab color and the code name of the macros.
+	props, err := d.file.GetSheetProps(inputSheet)
+	if err != nil {
+		return err
+	}
+	// The excelize version names the filter range as the criteria range, while Excel expects the filter database name.
+	// The name is swapped around the call, so the existing filter range is updated instead of added again.
+	d.renameDefinedNames(filterDatabaseName, criteriaName)
+	err = d.file.AutoFilter(inputSheet, "A1:"+lastCell, nil)
+	d.renameDefinedNames(criteriaName, filterDatabaseName)
+	if err != nil {
+		return err
+	}
+
+	return d.file.SetSheetProps(inputSheet, &props)
+}
+
+// renameDefinedNames renames the hidden built-in names of the input sheet.
+func (d *ResultDocument) renameDefinedNames(from, to string) {
+	sheet, err := d.file.GetSheetIndex(inputSheet)
+	if err != nil || d.file.WorkBook == nil || d.file.WorkBook.DefinedNames == nil {
+		return
+	}
+	for i, name := range d.file.WorkBook.DefinedNames.DefinedName {
+		if name.Name == from && name.Hidden && name.LocalSheetID != nil && *name.LocalSheetID == sheet {
+			d.file.WorkBook.DefinedNames.DefinedName[i].Name = to
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var (
+	// logLevel is the lowest level of the logged records, set with the --log-level flag.
+	logLevel = new(slog.LevelVar)
+	// logFormat is the format of the log records on the standard error, set with the --log-format flag.
+	logFormat = logFormatText
+	// logOutput is where the log records are written.
+	logOutput io.Writer = os.Stderr
+	// logFile is the file the log records are appended to, set with the --log-file flag.
+	logFile *os.File
+	// runLogger writes the records of the run to the log file only, e.g. the sent imports and the failed items, which
+	// are otherwise only shown by the progress messages.
+	runLogger *slog.Logger
+)
+
+func init() {
+	setupLogger()
+}
+
+// setupLogger makes the logger of the log format the default one. The records carry the run ID, so the logs of the
+// parallel runs can be told apart and matched with the server logs.
+func setupLogger() {
+	handler := newLogHandler(logOutput, consoleLogLevel{})
+	runLogger = nil
+	if logFile != nil {
+		fileHandler := newLogHandler(logFile, logLevel)
+		handler = fanoutHandler{handler, fileHandler}
+		runLogger = slog.New(fileHandler).With("runID", runID)
+	}
+	slog.SetDefault(slog.New(handler).With("runID", runID))
+}
+
+func newLogHandler(w io.Writer, level slog.Leveler) slog.Handler {
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: logTimeInLocation}
+	if logFormat == logFormatJSON {
+		return slog.NewJSONHandler(w, options)
+	}
+
+	return slog.NewTextHandler(w, options)
+}
+
+// consoleLogLevel is the lowest level of the records logged to the standard error: the --log-level, raised to the
+// errors in the quiet mode. The log file gets the records of the --log-level in the quiet mode too.
+type consoleLogLevel struct{}
+
+func (consoleLogLevel) Level() slog.Level {
+	if quiet {
+		return max(logLevel.Level(), slog.LevelError)
+	}
+
+	return logLevel.Level()
+}
+
+// logTimeInLocation renders the timestamps of the log records in the time zone of the --timezone flag.
+func logTimeInLocation(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && attr.Key == slog.TimeKey && attr.Value.Kind() == slog.KindTime {
+		attr.Value = slog.TimeValue(attr.Value.Time().In(timeLocation))
+	}
+
+	return attr
+}
+
+// logRun writes the record of the run to the log file, if there is one.
+func logRun(level slog.Level, msg string, args ...any) {
+	if runLogger != nil {
+		runLogger.Log(context.Background(), level, msg, args...)
+	}
+}
+
+// logImportResult writes the outcome of the import and the errors of its failed items to the log file.
+func logImportResult(result importResult) {
+	if runLogger == nil {
+		return
+	}
+	if result.err != nil {
+		logRun(slog.LevelError, "import failed", "location", result.location, "duration", result.duration, "error", result.err)
+		return
+	}
+	if result.response == nil {
+		return
+	}
+
+	processed, failed := 0, 0
+	for _, item := range result.response.ImportItems {
+		switch status, message := itemOutcome(item, "unknown error"); status {
+		case ImportItemStatusProcessed:
+			processed++
+		case ImportItemStatusFailed:
+			failed++
+			logRun(slog.LevelWarn, "item failed", "location", result.location, "item", item.ID, "error", message)
+		}
+	}
+	logRun(slog.LevelInfo, "import finished", "location", result.location, "duration", result.duration, "items", len(result.response.ImportItems), "processed", processed, "failed", failed)
+}
+
+// fanoutHandler passes the records to all its handlers, e.g. to the standard error and to the log file.
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, r.Level) {
+			errs = append(errs, handler.Handle(ctx, r.Clone()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+
+	return handlers
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+
+	return handlers
+}
+
+// logLevelValue is the flag value of the log level: debug, info, warn or error.
+type logLevelValue struct{}
+
+func (logLevelValue) String() string {
+	return strings.ToLower(logLevel.Level().String())
+}
+
+func (logLevelValue) Set(value string) error {
+	if err := logLevel.UnmarshalText([]byte(value)); err != nil {
+		return fmt.Errorf("log level %q is not supported, use debug, info, warn or error", value)
+	}
+
+	return nil
+}
+
+// logFormatValue is the flag value of the log format: text or json. With the JSON format, the console messages are
+// logged as well, so the whole output of a run can be parsed, e.g. in the CI.
+type logFormatValue struct{}
+
+func (logFormatValue) String() string {
+	return logFormat
+}
+
+func (logFormatValue) Set(value string) error {
+	switch value {
+	case logFormatText:
+		if _, ok := console.(*logWriter); ok {
+			console = os.Stdout
+		}
+	case logFormatJSON:
+		console = &logWriter{}
+	default:
+		return fmt.Errorf("log format %q is not supported, use %s or %s", value, logFormatText, logFormatJSON)
+	}
+	logFormat = value
+	setupLogger()
+
+	return nil
+}
+
+// logFileValue is the flag value of the log file. The file is opened for appending, so the log of the scheduled runs
+// grows with every run.
+type logFileValue struct{}
+
+func (logFileValue) String() string {
+	if logFile == nil {
+		return ""
+	}
+
+	return logFile.Name()
+}
+
+func (logFileValue) Set(value string) error {
+	file, err := os.OpenFile(value, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("log file can't be opened: %w", err)
+	}
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+	logFile = file
+	setupLogger()
+
+	return nil
+}
+
+// logWriter writes the console messages as the info records of the log, one record per line.
+type logWriter struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSpace(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+		if line != "" {
+			slog.Info(line)
+		}
+	}
+
+	return len(p), nil
+}
+
+// fatal logs the error in the language selected with the --lang flag and exits with the status 1.
+func fatal(v any) {
+	slog.Error(tr(fmt.Sprint(v)))
+	os.Exit(1)
+}
+
+// fatalf logs the formatted error and exits with the status 1.
+func fatalf(format string, a ...any) {
+	fatal(trf(format, a...))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+)
+
+// gramsLikeMass is the mass in kilograms from which a mass looks like it was entered in grams.
+const gramsLikeMass = 1000
+
+// checkMasses returns the problems of the item masses that are likely data entry errors, so they are noticed before
+// the import is sent. The problems are translated.
+func checkMasses(item ImportItemRequest) []string {
+	var problems []string
+	if item.GrossMass != nil && *item.GrossMass < 0 {
+		problems = append(problems, trf("gross mass %g is negative", *item.GrossMass))
+	}
+	if item.NetMass != nil && *item.NetMass < 0 {
+		problems = append(problems, trf("net mass %g is negative", *item.NetMass))
+	}
+	if item.GrossMass != nil && item.NetMass != nil && *item.NetMass > *item.GrossMass {
+		problems = append(problems, trf("net mass %g is larger than the gross mass %g", *item.NetMass, *item.GrossMass))
+	}
+	if isKilograms(item.WeightUnit) {
+		for _, mass := range []*float64{item.GrossMass, item.NetMass} {
+			if mass != nil && *mass >= gramsLikeMass {
+				problems = append(problems, trf("mass %g kg looks like grams entered as kilograms", *mass))
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// isKilograms reports whether the masses are in kilograms, which they are without the weight unit.
+func isKilograms(weightUnit *string) bool {
+	if weightUnit == nil {
+		return true
+	}
+	unit := strings.ToLower(strings.TrimSpace(*weightUnit))
+
+	return unit == "" || unit == "kg" || unit == "kgm"
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metadataSheet is the hidden sheet of the xlsx outputs with the metadata of the run, so a result file can be traced
+// back to its imports later.
+const metadataSheet = "customs-meta"
+
+// addImport records the location of an import the results come from.
+func (d *ResultDocument) addImport(location string) {
+	d.imports = append(d.imports, location)
+}
+
+// writeMetadata replaces the metadata sheet of the workbook and hides it.
+func (d *ResultDocument) writeMetadata() error {
+	index, err := d.file.GetSheetIndex(metadataSheet)
+	if err != nil {
+		return err
+	}
+	if index >= 0 {
+		if err = d.file.DeleteSheet(metadataSheet); err != nil {
+			return err
+		}
+	}
+	if _, err = d.file.NewSheet(metadataSheet); err != nil {
+		return err
+	}
+
+	for i, row := range d.metadata() {
+		if err = d.file.SetSheetRow(metadataSheet, fmt.Sprintf("A%d", i+1), &row); err != nil {
+			return err
+		}
+	}
+
+	return d.file.SetSheetVisible(metadataSheet, false)
+}
+
+// metadata returns the rows of the metadata sheet: the run, the imports and the number of the item results by the
+// customs territory and the status.
+func (d *ResultDocument) metadata() [][]string {
+	ids := make([]string, len(d.imports))
+	for i, location := range d.imports {
+		ids[i] = path.Base(location)
+	}
+
+	rows := [][]string{
+		{"run ID", runID},
+		{"import IDs", strings.Join(ids, ", ")},
+		{"import locations", strings.Join(d.imports, ", ")},
+		{"server URL", url},
+		{"CLI version", version},
+		{"run started at", formatTime(runStartedAt)},
+		{"written at", formatTime(time.Now())},
+	}
+	if env != "" {
+		rows = append(rows, []string{"environment", env})
+	}
+	for _, territory := range allowedCustomsTerritories {
+		var processed, review, failed, notProcessed int
+		for _, result := range d.results {
+			territoryResult, ok := result.Territories[territory]
+			if !ok {
+				continue
+			}
+			switch territoryResult.Status {
+			case ImportItemStatusProcessed:
+				processed++
+			case resultStatusReview:
+				review++
+			case ImportItemStatusPending, ImportItemStatusProcessing:
+				notProcessed++
+			default:
+				failed++
+			}
+		}
+		name := strings.ToUpper(territory)
+		rows = append(rows,
+			[]string{"items " + name + " processed", strconv.Itoa(processed)},
+			[]string{"items " + name + " review", strconv.Itoa(review)},
+			[]string{"items " + name + " failed", strconv.Itoa(failed)},
+			[]string{"items " + name + " not processed", strconv.Itoa(notProcessed)},
+		)
+	}
+
+	return rows
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// outputWebhook is a shorthand for the JSON results posted to a URL.
+	outputWebhook = "webhook"
+
+	outputStdout = "-"
+)
+
+// OutputWriter writes the results of a run to an output. The writers are registered by the format name, which selects
+// the writer in the --output flag (e.g. "csv:result.csv") and in the outputs of the configuration file.
+type OutputWriter interface {
+	WriteOutput(output OutputConfig, doc *ResultDocument) error
+}
+
+// EncodingOutputWriter is an OutputWriter of a file format. The encoded results are written to the output destination,
+// which is a file path, "-" for the standard output, or an http(s) URL the results are uploaded to.
+type EncodingOutputWriter struct {
+	ContentType string
+	Encode      func(w io.Writer, doc *ResultDocument) error
+}
+
+var outputWriters = map[string]OutputWriter{}
+
+// RegisterOutputWriter makes the output writer available by the format name. It panics if the format is already
+// registered, so it is meant to be called from the init functions.
+func RegisterOutputWriter(format string, writer OutputWriter) {
+	if _, ok := outputWriters[format]; ok {
+		panic(fmt.Sprintf("output writer %q is already registered", format))
+	}
+	outputWriters[format] = writer
+}
+
+// outputFormats returns the names of the registered output writers.
+func outputFormats() []string {
+	formats := make([]string, 0, len(outputWriters))
+	for format := range outputWriters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// OutputConfig is a destination the results are written to.
+type OutputConfig struct {
+	Format      string            `json:"format"`
+	Destination string            `json:"destination"`       // file path, "-" for the standard output, or http(s) URL
+	Method      string            `json:"method,omitempty"`  // HTTP method for the URL destination (default PUT)
+	Headers     map[string]string `json:"headers,omitempty"` // HTTP headers for the URL destination
+}
+
+// outputsValue is the repeatable --output flag. The value is either a path of the xlsx file, or a destination
+// prefixed with the format, e.g. "json:-" or "webhook:https://example.com/hook".
+type outputsValue []OutputConfig
+
+func (o *outputsValue) Set(s string) error {
+	output := OutputConfig{
+		Format:      outputFormatXLSX,
+		Destination: s,
+	}
+	if format, destination, ok := strings.Cut(s, ":"); ok {
+		if _, registered := outputWriters[format]; registered {
+			output.Format = format
+			output.Destination = destination
+		} else if format == outputWebhook {
+			output.Format = outputFormatJSON
+			output.Destination = destination
+			output.Method = http.MethodPost
+		}
+	}
+	if output.Destination == "" {
+		return fmt.Errorf("missing output destination in %q", s)
+	}
+	*o = append(*o, output)
+
+	return nil
+}
+
+func (o *outputsValue) String() string {
+	destinations := make([]string, len(*o))
+	for i, output := range *o {
+		destinations[i] = output.Format + ":" + output.Destination
+	}
+
+	return strings.Join(destinations, ",")
+}
+
+func (o OutputConfig) validate() error {
+	if _, ok := outputWriters[o.Format]; !ok {
+		return fmt.Errorf("output format %q is not supported, supported formats are %s", o.Format, strings.Join(outputFormats(), ", "))
+	}
+	if o.Destination == "" {
+		return fmt.Errorf("missing output destination")
+	}
+
+	return nil
+}
+
+// isFile reports whether the output is written to a local file.
+func (o OutputConfig) isFile() bool {
+	return o.Destination != outputStdout && !isURL(o.Destination)
+}
+
+func (o OutputConfig) String() string {
+	if o.Destination == outputStdout {
+		return "standard output"
+	}
+
+	return o.Destination
+}
+
+// writeOutput writes the results with the writer registered for the output format.
+func writeOutput(output OutputConfig, doc *ResultDocument) error {
+	writer, ok := outputWriters[output.Format]
+	if !ok {
+		return fmt.Errorf("output format %q is not supported", output.Format)
+	}
+	if mergeOutputs && output.isFile() {
+		var err error
+		if doc, err = mergeExistingOutput(output, doc); err != nil {
+			return err
+		}
+	}
+
+	return writer.WriteOutput(output, doc)
+}
+
+func (e EncodingOutputWriter) WriteOutput(output OutputConfig, doc *ResultDocument) error {
+	var buf bytes.Buffer
+	err := e.Encode(&buf, doc)
+	if err != nil {
+		return err
+	}
+
+	return writeDestination(output, e.ContentType, buf.Bytes())
+}
+
+// writeDestination writes the encoded output to its destination: the file, the standard output or the URL.
+func writeDestination(output OutputConfig, contentType string, data []byte) error {
+	switch {
+	case output.Destination == outputStdout:
+		_, err := os.Stdout.Write(data)
+		return err
+	case isURL(output.Destination):
+		return uploadOutput(output, contentType, data)
+	default:
+		return writeFile(output.Destination, data)
+	}
+}
+
+// writeFile replaces the file with the data. The data is written to a temporary file that is renamed at the end, so an
+// interrupted run never leaves a partially written file behind.
+func writeFile(path string, data []byte) error {
+	// Devices and pipes, e.g. /dev/null, can't be replaced.
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		return os.WriteFile(path, data, 0o644)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Remove the temporary file if it is not renamed.
+		_ = os.Remove(file.Name())
+	}()
+
+	_, err = file.Write(data)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(file.Name(), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// uploadOutput sends the output to the URL, e.g. an S3 pre-signed URL or a webhook.
+func uploadOutput(output OutputConfig, contentType string, data []byte) error {
+	method := output.Method
+	if method == "" {
+		method = http.MethodPut
+	}
+
+	req, err := http.NewRequest(method, output.Destination, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", userAgent())
+	for name, value := range output.Headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code while writing the output to %s %d\n%s\n", output.Destination, res.StatusCode, string(resBody))
+	}
+
+	return nil
+}
+
+func isURL(destination string) bool {
+	return strings.HasPrefix(destination, "http://") || strings.HasPrefix(destination, "https://")
+}
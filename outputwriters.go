@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+const (
+	outputFormatXLSX = "xlsx"
+	outputFormatCSV  = "csv"
+	outputFormatJSON = "json"
+)
+
+func init() {
+	RegisterOutputWriter(outputFormatXLSX, EncodingOutputWriter{
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Encode:      encodeXLSX,
+	})
+	RegisterOutputWriter(outputFormatCSV, EncodingOutputWriter{
+		ContentType: "text/csv",
+		Encode:      encodeCSV,
+	})
+	RegisterOutputWriter(outputFormatJSON, EncodingOutputWriter{
+		ContentType: "application/json",
+		Encode:      encodeJSON,
+	})
+}
+
+// encodeXLSX writes the input workbook with the result columns.
+func encodeXLSX(w io.Writer, doc *ResultDocument) error {
+	file, err := doc.Workbook()
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteTo(w)
+
+	return err
+}
+
+// encodeCSV writes the rows of the input sheet with the result columns.
+func encodeCSV(w io.Writer, doc *ResultDocument) error {
+	cw := csv.NewWriter(w)
+	err := doc.eachRow(func(_ int, row []string) error {
+		return cw.Write(row)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// encodeJSON writes the results of the items.
+func encodeJSON(w io.Writer, doc *ResultDocument) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	// The timestamps are in the selected time zone, with the offset.
+	return encoder.Encode(struct {
+		RunID       string        `json:"runId"`
+		Environment string        `json:"environment,omitempty"`
+		StartedAt   time.Time     `json:"startedAt"`
+		Items       []*ItemResult `json:"items"`
+	}{
+		RunID:       runID,
+		Environment: env,
+		StartedAt:   runStartedAt.In(timeLocation).Truncate(time.Second),
+		Items:       doc.Results(),
+	})
+}
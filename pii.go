@@ -0,0 +1,79 @@
+package main
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// The kinds of the personal data removed by --scrub-pii.
+const (
+	piiEmail = "email"
+	piiPhone = "phone number"
+	piiName  = "contact name"
+)
+
+// piiPatterns match the obvious personal data in the free texts of the items. They are kept narrow, so the product
+// data is not mistaken for the personal data: the phone numbers need a "+" country code or a phone keyword, so the
+// article numbers and the EANs starting with "00" are not phone numbers, and the names need a salutation or a contact
+// keyword. "Dr" is not a salutation of the names, because it is also a part of the brands, e.g. "Dr. Martens".
+var piiPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{piiEmail, regexp.MustCompile(`[\p{L}\d._%+-]+@[\p{L}\d.-]+\.\p{L}{2,}`)},
+	{piiPhone, regexp.MustCompile(`(?i:(?:tel|phone|mobile|mob|fax|telefon|handy|mobil|tlf)\.?:?\s*)?\+\d{1,3}[\s./-]?(?:\(\d+\)[\s./-]?)?\d[\d\s./-]{5,}\d`)},
+	{piiPhone, regexp.MustCompile(`(?i:\b(?:tel|phone|mobile|mob|fax|telefon|handy|mobil|tlf)\.?:?\s*)\(?\d[\d\s()./-]{5,}\d`)},
+	{piiName, regexp.MustCompile(`\b(?:(?:Mr|Mrs|Ms|Herr|Frau)\.?|(?:Hr|Fr)\.)\s+\p{Lu}\p{L}+(?:\s+\p{Lu}\p{L}+)?`)},
+	{piiName, regexp.MustCompile(`(?i:\b(?:contact|attn|c/o|kontakt|ansprechpartner|z\. ?hd\.?))[:\s]+\p{Lu}\p{L}+(?:\s+\p{Lu}\p{L}+)?`)},
+}
+
+// scrubPII removes the personal data from the free texts of the item: the name, the description and the attributes.
+// It returns the kinds of the removed data, so the item can be reported without repeating the data.
+func scrubPII(item *ImportItemRequest) []string {
+	var kinds []string
+	scrub := func(text string) string {
+		for _, p := range piiPatterns {
+			if p.pattern.MatchString(text) {
+				text = p.pattern.ReplaceAllString(text, " ")
+				if !slices.Contains(kinds, p.kind) {
+					kinds = append(kinds, p.kind)
+				}
+			}
+		}
+		return text
+	}
+
+	item.Name = scrubbedText(item.Name, scrub)
+	item.Description = scrubbedText(item.Description, scrub)
+	for key, value := range item.Attributes {
+		item.Attributes[key] = scrubbedText(value, scrub)
+	}
+
+	return kinds
+}
+
+// detectPII returns the kinds of the personal data in the free texts of the item, without removing it.
+func detectPII(item ImportItemRequest) []string {
+	item.Attributes = maps.Clone(item.Attributes)
+
+	return scrubPII(&item)
+}
+
+// leftoverSeparators are the separators of the removed parts, e.g. ", ," of "Smith, smith@acme.com, +47 ...".
+var leftoverSeparators = regexp.MustCompile(`(?:\s*,)+\s*,`)
+
+// scrubbedText returns the text without the removed parts and the spaces and separators they leave behind. The
+// unchanged text is returned as it is.
+func scrubbedText(text string, scrub func(string) string) string {
+	scrubbed := scrub(text)
+	if scrubbed == text {
+		return text
+	}
+
+	scrubbed = strings.Join(strings.Fields(scrubbed), " ")
+	scrubbed = leftoverSeparators.ReplaceAllString(scrubbed, ",")
+
+	return strings.TrimRight(scrubbed, " ,;:")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScrubPII(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		want      string
+		wantKinds []string
+	}{
+		{"email", "Cotton shirt, contact sales@acme.com for samples", "Cotton shirt, contact for samples", []string{piiEmail}},
+		{"phone with country code", "Cotton shirt +47 912 34 567", "Cotton shirt", []string{piiPhone}},
+		{"phone with keyword", "Cotton shirt, tel. 0711 123456", "Cotton shirt", []string{piiPhone}},
+		{"name with salutation", "Samples from Mr. John Smith", "Samples from", []string{piiName}},
+		{"name with contact keyword", "Cotton shirt, Ansprechpartner: Anna Berg", "Cotton shirt", []string{piiName}},
+		{"several kinds", "Shirt, Attn: Ola Nordmann, ola@example.no, +47 912 34 567", "Shirt", []string{piiEmail, piiPhone, piiName}},
+		{"dimensions", "Table 120 x 80 x 75 cm, 25.5 kg", "Table 120 x 80 x 75 cm, 25.5 kg", nil},
+		{"commodity code", "Shirt of cotton, heading 6205 20 00 00", "Shirt of cotton, heading 6205 20 00 00", nil},
+		{"article number", "Spare part 4711-0815-42", "Spare part 4711-0815-42", nil},
+		{"article number with 00", "Boot, article 0049123456789, leather", "Boot, article 0049123456789, leather", nil},
+		{"EAN with 00", "EAN 0012345678905", "EAN 0012345678905", nil},
+		{"brand with Dr", "Dr. Martens 1460 boots", "Dr. Martens 1460 boots", nil},
+		{"brand with Dr without the dot", "Dr Oetker baking powder", "Dr Oetker baking powder", nil},
+		{"Swiss francs", "Price Fr 20 per piece", "Price Fr 20 per piece", nil},
+		{"at sign without a domain", "Cable @ 5 m", "Cable @ 5 m", nil},
+		{"phone with 00 and keyword", "Shirt, tel. 0049 711 123456", "Shirt", []string{piiPhone}},
+		{"name with a dotted abbreviation", "Shirt, Hr. Hansen", "Shirt", []string{piiName}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := ImportItemRequest{Description: tt.text}
+			kinds := scrubPII(&item)
+			if item.Description != tt.want {
+				t.Errorf("scrubPII() description = %q, want %q", item.Description, tt.want)
+			}
+			slices.Sort(kinds)
+			slices.Sort(tt.wantKinds)
+			if !slices.Equal(kinds, tt.wantKinds) {
+				t.Errorf("scrubPII() kinds = %q, want %q", kinds, tt.wantKinds)
+			}
+		})
+	}
+}
+
+func TestDetectPII(t *testing.T) {
+	item := ImportItemRequest{
+		Name:        "Shirt",
+		Description: "Contact: John Smith",
+		Attributes:  map[string]string{"supplier": "sales@acme.com"},
+	}
+	kinds := detectPII(item)
+	slices.Sort(kinds)
+	if want := []string{piiName, piiEmail}; !slices.Equal(kinds, want) {
+		t.Errorf("detectPII() = %q, want %q", kinds, want)
+	}
+	if item.Description != "Contact: John Smith" || item.Attributes["supplier"] != "sales@acme.com" {
+		t.Errorf("detectPII() changed the item to %+v", item)
+	}
+}
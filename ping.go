@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+func runPing(args []string) {
+	fs := newCommandFlagSet("ping")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Check that the server can be reached and that it accepts the API key, without importing anything. The proxy,
+	TLS and configuration flags are applied, so the setup of a new environment can be checked before the first import.
+
+	Options:
+		--help		display this help and exit
+
+	Example:
+		customs ping --api-key "yourApiKey"
+
+`)
+
+		os.Exit(0)
+	}
+
+	setupRun()
+	client := newAPIClient(url, apiKey)
+	ctx := context.Background()
+
+	started := time.Now()
+	server, err := client.GetVersion(ctx)
+	if err != nil {
+		fatalf("the server %s can't be reached: %s", url, err)
+	}
+	fmt.Println(trf("The server %s is reachable (version %s, %s).", url, server.Version, time.Since(started).Round(time.Millisecond)))
+
+	account, err := client.GetAccount(ctx)
+	if errors.Is(err, customs.ErrUnauthorized) {
+		fatal("the API key is not valid or not authorized, please check the --api-key flag")
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if account.Name != "" {
+		fmt.Println(trf("The API key is valid for the account %s.", account.Name))
+	} else {
+		fmt.Println(tr("The API key is valid."))
+	}
+}
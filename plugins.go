@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PluginsConfig holds the input and output formats provided by the external commands, by the format name, so the
+// proprietary formats can be added without changing the CLI.
+type PluginsConfig struct {
+	Inputs  map[string]PluginConfig `json:"inputs"`
+	Outputs map[string]PluginConfig `json:"outputs"`
+}
+
+// PluginConfig is an input or output format provided by an external command. The command is run by the shell with the
+// path of the input or the output destination as the argument:
+//
+//   - An input command writes the items to the standard output in the JSON input format, an array of objects with the
+//     headings as the keys.
+//   - An output command reads the results in the JSON output format from the standard input and writes them to the
+//     destination.
+type PluginConfig struct {
+	Command    string   `json:"command"`
+	Extensions []string `json:"extensions,omitempty"` // file extensions of the input format, e.g. [".edi"]
+}
+
+// registeredPlugins are the formats of the plugins registered by the run, so the configuration can be applied again.
+var registeredPlugins = map[string]bool{}
+
+func (c PluginsConfig) validate() error {
+	for format, plugin := range c.Inputs {
+		_, builtIn := inputReaders[format]
+		if err := plugin.validate(builtIn && !registeredPlugins["input:"+format]); err != nil {
+			return fmt.Errorf("inputs.%s: %w", format, err)
+		}
+	}
+	for format, plugin := range c.Outputs {
+		_, builtIn := outputWriters[format]
+		if err := plugin.validate(builtIn && !registeredPlugins["output:"+format]); err != nil {
+			return fmt.Errorf("outputs.%s: %w", format, err)
+		}
+		if len(plugin.Extensions) > 0 {
+			return fmt.Errorf("outputs.%s: the extensions are only used by the input formats", format)
+		}
+	}
+
+	return nil
+}
+
+func (p PluginConfig) validate(builtIn bool) error {
+	if builtIn {
+		return fmt.Errorf("the format is built in")
+	}
+	if strings.TrimSpace(p.Command) == "" {
+		return fmt.Errorf("missing command")
+	}
+	for _, extension := range p.Extensions {
+		if !strings.HasPrefix(extension, ".") {
+			return fmt.Errorf("extension %q must start with a dot", extension)
+		}
+	}
+
+	return nil
+}
+
+// registerPlugins registers the input readers and the output writers of the plugins. The --output flags of the plugin
+// formats are parsed before the configuration is read, so their format is set here.
+func registerPlugins(plugins PluginsConfig) {
+	for format, plugin := range plugins.Inputs {
+		if registeredPlugins["input:"+format] {
+			continue
+		}
+		registeredPlugins["input:"+format] = true
+		RegisterInputReader(format, func(path string) (InputReader, error) {
+			return openPluginInput(plugin.Command, path)
+		}, plugin.Extensions...)
+	}
+	for format, plugin := range plugins.Outputs {
+		if registeredPlugins["output:"+format] {
+			continue
+		}
+		registeredPlugins["output:"+format] = true
+		RegisterOutputWriter(format, pluginOutputWriter{command: plugin.Command})
+	}
+
+	for i, output := range outputs {
+		format, destination, ok := strings.Cut(output.Destination, ":")
+		if _, plugin := plugins.Outputs[format]; ok && plugin && output.Format == outputFormatXLSX {
+			outputs[i].Format = format
+			outputs[i].Destination = destination
+		}
+	}
+}
+
+// openPluginInput reads the items of the input from the standard output of the plugin command.
+func openPluginInput(command, path string) (InputReader, error) {
+	cmd := shellCommand(context.Background(), command, path)
+	if path == inputStdin {
+		cmd.Stdin = os.Stdin
+	}
+	cmd.Stderr = os.Stderr
+	var items bytes.Buffer
+	cmd.Stdout = &items
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("input plugin %q failed for %q: %w", command, path, err)
+	}
+
+	return newJSONReader(path, &items)
+}
+
+// pluginOutputWriter writes the results with the output command of a plugin.
+type pluginOutputWriter struct {
+	command string
+}
+
+func (w pluginOutputWriter) WriteOutput(output OutputConfig, doc *ResultDocument) error {
+	var results bytes.Buffer
+	if err := encodeJSON(&results, doc); err != nil {
+		return err
+	}
+
+	cmd := shellCommand(context.Background(), w.command, output.Destination)
+	cmd.Stdin = &results
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("output plugin %q failed for %q: %w", w.command, output.Destination, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// confirm asks the operator a yes/no question on the standard input. The question is skipped when the --yes flag is set.
+// If the standard input is not interactive, the question is treated as declined, so automated runs never hang on a prompt.
+func confirm(question string) error {
+	if assumeYes {
+		return nil
+	}
+
+	if !isInteractive() {
+		return fmt.Errorf("%w: %s (use --yes to confirm in non-interactive runs)", ErrNotConfirmed, question)
+	}
+
+	fmt.Fprint(promptOutput(), trf("%s [y/N]: ", question))
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNotConfirmed, question)
+	}
+
+	if !isYes(answer) {
+		return fmt.Errorf("%w: %s", ErrNotConfirmed, question)
+	}
+
+	return nil
+}
+
+// isYes reports whether the answer to a yes/no question is yes, in any of the languages.
+func isYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "j", "ja":
+		return true
+	default:
+		return false
+	}
+}
+
+// ask asks the operator a question on the standard input and returns the answer. It fails if the standard input is not
+// interactive.
+func ask(question string) (string, error) {
+	if !isInteractive() {
+		return "", fmt.Errorf("standard input is not interactive")
+	}
+
+	fmt.Fprintf(promptOutput(), "%s ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
+// promptOutput returns where the questions are asked. The console is discarded in the quiet mode, so the questions are
+// asked on the standard error then.
+func promptOutput() io.Writer {
+	if quiet {
+		return os.Stderr
+	}
+
+	return console
+}
+
+// isInteractive reports whether the standard input is a terminal.
+func isInteractive() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// confirmOverwrite asks for the confirmation if the file at the given path already exists.
+func confirmOverwrite(path string) error {
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return confirm(trf("Output file %q already exists, overwrite it?", path))
+}
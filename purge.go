@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+// purgeImports deletes the imports of the results from the server once the results are written, with
+// --purge-after-fetch. Every deletion is verified by fetching the import again, which must fail with ErrNotFound.
+func purgeImports(ctx context.Context, locations []string) error {
+	client := newAPIClient(url, apiKey)
+	for _, importLocation := range locations {
+		err := client.DeleteImport(ctx, importLocation)
+		if errors.Is(err, customs.ErrNotSupported) {
+			return errors.New(tr("the server doesn't delete the imports, the results are kept on the server"))
+		}
+		// An import deleted by an earlier attempt is verified like the others.
+		if err != nil && !errors.Is(err, customs.ErrNotFound) {
+			return fmt.Errorf("import %s%s can't be deleted: %w", url, importLocation, err)
+		}
+
+		_, err = client.GetImport(ctx, importLocation)
+		if err == nil {
+			return errors.New(trf("import %s%s is still on the server after the deletion", url, importLocation))
+		}
+		if !errors.Is(err, customs.ErrNotFound) {
+			return fmt.Errorf("deletion of the import %s%s can't be verified: %w", url, importLocation, err)
+		}
+		logRun(slog.LevelInfo, "import deleted", "location", importLocation)
+	}
+	fmt.Fprint(console, "\n"+trf("%d imports are deleted from the server.", len(locations))+"\n")
+
+	return nil
+}
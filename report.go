@@ -0,0 +1,482 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const defaultAggregateOutput = "aggregate.xlsx"
+
+// reportCommands are the subcommands of the report command.
+var reportCommands = map[string]func(args []string){
+	"aggregate": runReportAggregate,
+}
+
+func runReport(args []string) {
+	if len(args) == 0 {
+		fatal("please provide the report command, the supported commands are: aggregate")
+	}
+	command, ok := reportCommands[args[0]]
+	if !ok {
+		fatalf("report command %q is not supported, the supported commands are: aggregate", args[0])
+	}
+
+	command(args[1:])
+}
+
+// resultFile is a result file of a previous run.
+type resultFile struct {
+	path     string
+	modTime  time.Time
+	headings []string
+	rows     [][]string
+}
+
+// aggregatedItem is the latest result of an item across the result files.
+type aggregatedItem struct {
+	values      map[string]string // input values by the heading
+	territories map[string]string // result cells by the customs territory
+	details     map[string]string // code detail cells by the untranslated heading, e.g. "description EU"
+	updatedAt   time.Time
+	warnings    string
+}
+
+func runReportAggregate(args []string) {
+	var glob string
+	fs := newCommandFlagSet("report aggregate")
+	fs.StringVar(&glob, "glob", "", "")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Merge the result files of several runs into one output with the latest result of every item.
+
+	Options:
+		--glob		pattern of the result files, e.g. 'results/2024-*.xlsx'. The files can also be given as the arguments
+		--output	write output to the file (default %q), see "customs --help" for the formats and destinations
+		--help		display this help and exit
+
+	Example:
+		customs report aggregate --glob 'results/2024-*.xlsx' --output json:erp.json
+
+`, defaultAggregateOutput)
+
+		os.Exit(0)
+	}
+
+	setupQuiet()
+	setupColumnAliases()
+	paths := fs.Args()
+	if glob != "" {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			fatal(err)
+		}
+		paths = append(paths, matches...)
+	}
+
+	if len(outputs) == 0 {
+		outputs = outputsValue{{Format: outputFormatXLSX, Destination: defaultAggregateOutput}}
+	}
+	// The glob of the result files may match the outputs of the previous aggregation, which are not results of a run.
+	paths = slices.DeleteFunc(paths, func(path string) bool {
+		if isOutputFile(path) {
+			warnf("the file %q is skipped, it is an output of the aggregation", path)
+			return true
+		}
+		return false
+	})
+	if len(paths) == 0 {
+		fatal("no result files to aggregate, please provide the --glob flag or the result file paths")
+	}
+	for _, output := range outputs {
+		if output.Destination == outputStdout && console == os.Stdout {
+			console = os.Stderr
+		}
+	}
+	for _, output := range outputs {
+		if !output.isFile() {
+			continue
+		}
+		if err := confirmOverwrite(output.Destination); err != nil {
+			fatal(err)
+		}
+	}
+
+	var files []resultFile
+	for _, path := range paths {
+		file, err := readResultFile(path)
+		if err != nil {
+			fatal(err)
+		}
+		files = append(files, file)
+	}
+	// The later files take precedence for the items without the update time.
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	doc, err := aggregateResults(files)
+	if err != nil {
+		fatal(err)
+	}
+	for _, output := range outputs {
+		err = writeOutput(output, doc)
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	fmt.Fprintln(console, trf("%d items from %d result files are aggregated.", len(doc.results), len(files)))
+	for _, output := range outputs {
+		fmt.Fprintln(console, trf("The output is written to: %q", output))
+	}
+	printOutputPaths(outputs)
+}
+
+// readResultFile reads the rows of the result xlsx or CSV file.
+func readResultFile(path string) (resultFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return resultFile{}, err
+	}
+
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx", ".xlsm":
+		file, err := excelize.OpenFile(path)
+		if err != nil {
+			return resultFile{}, err
+		}
+		rows, err = file.GetRows(inputSheet)
+		_ = file.Close()
+		if err != nil {
+			return resultFile{}, err
+		}
+	case ".csv":
+		file, err := os.Open(path)
+		if err != nil {
+			return resultFile{}, err
+		}
+		cr := csv.NewReader(file)
+		cr.FieldsPerRecord = -1
+		rows, err = cr.ReadAll()
+		_ = file.Close()
+		if err != nil {
+			return resultFile{}, fmt.Errorf("invalid CSV file %q: %w", path, err)
+		}
+	default:
+		return resultFile{}, fmt.Errorf("format of the result file %q is not supported, use xlsx or csv", path)
+	}
+	if len(rows) == 0 {
+		return resultFile{}, fmt.Errorf("result file %q is empty", path)
+	}
+
+	return resultFile{path: path, modTime: info.ModTime(), headings: rows[0], rows: rows[1:]}, nil
+}
+
+// resultColumns returns the indexes of the result columns of the file by the customs territory. The headings can be
+// in any of the languages.
+func (f resultFile) resultColumns() (map[string]int, error) {
+	resultColumns := map[string]int{}
+	for _, territory := range allowedCustomsTerritories {
+		if i := getTranslatedColumnIndex(f.headings, "result "+strings.ToUpper(territory)); i != nil {
+			resultColumns[territory] = *i
+		}
+	}
+	if len(resultColumns) == 0 {
+		return nil, fmt.Errorf("result file %q has no result columns", f.path)
+	}
+
+	return resultColumns, nil
+}
+
+// aggregateResults merges the result files into one document. An item is kept once, with the input values of its
+// latest result and the latest result of every customs territory.
+func aggregateResults(files []resultFile) (*ResultDocument, error) {
+	var inputHeadings []string
+	details := map[string]bool{} // code details of any of the files by the heading
+	items := map[string]*aggregatedItem{}
+	var ids []string
+	for _, file := range files {
+		idColumn := getColumnIndex(file.headings, "id")
+		if idColumn == nil {
+			return nil, fmt.Errorf("result file %q has no %q column", file.path, "id")
+		}
+		resultColumns, err := file.resultColumns()
+		if err != nil {
+			return nil, err
+		}
+		detailColumns := map[string]int{}
+		for _, territory := range allowedCustomsTerritories {
+			for _, detail := range codeDetails {
+				if i := getTranslatedColumnIndex(file.headings, detail.key(territory)); i != nil {
+					detailColumns[detail.key(territory)] = *i
+					details[detail.heading] = true
+				}
+			}
+		}
+		updatedColumn := getTranslatedColumnIndex(file.headings, "result updated at")
+		warningColumn := getTranslatedColumnIndex(file.headings, "warnings")
+		// The compliance review flag is derived from the measures again.
+		reviewColumn := getTranslatedColumnIndex(file.headings, "compliance review")
+		if len(inputHeadings) == 0 {
+			inputHeadings = []string{file.headings[*idColumn]}
+		}
+
+		for _, row := range file.rows {
+			id := getCell(row, *idColumn)
+			if id == "" {
+				continue
+			}
+			item, ok := items[id]
+			if !ok {
+				item = &aggregatedItem{territories: map[string]string{}, details: map[string]string{}}
+				items[id] = item
+				ids = append(ids, id)
+			}
+
+			var updatedAt time.Time
+			if updatedColumn != nil {
+				updatedAt, _ = time.ParseInLocation(timeLayout, getCell(row, *updatedColumn), timeLocation)
+			}
+			if updatedAt.Before(item.updatedAt) {
+				continue
+			}
+			item.updatedAt = updatedAt
+			if warningColumn != nil {
+				item.warnings = getCell(row, *warningColumn)
+			}
+
+			item.values = map[string]string{}
+			for i, heading := range file.headings {
+				if i == valueOrMinusOne(updatedColumn) || i == valueOrMinusOne(warningColumn) || i == valueOrMinusOne(reviewColumn) ||
+					isResultColumn(resultColumns, i) || isResultColumn(detailColumns, i) {
+					continue
+				}
+				if getColumnIndex(inputHeadings, heading) == nil {
+					inputHeadings = append(inputHeadings, heading)
+				}
+				item.values[strings.ToLower(strings.TrimSpace(heading))] = getCell(row, i)
+			}
+			for territory, i := range resultColumns {
+				if cell := getCell(row, i); cell != "" {
+					item.territories[territory] = cell
+				}
+			}
+			for key, i := range detailColumns {
+				if cell := getCell(row, i); cell != "" {
+					item.details[key] = cell
+				}
+			}
+		}
+	}
+
+	var fileDetails []codeDetail
+	for _, detail := range codeDetails {
+		if details[detail.heading] {
+			fileDetails = append(fileDetails, detail)
+		}
+	}
+
+	return newAggregatedDocument(inputHeadings, ids, items, fileDetails)
+}
+
+// newAggregatedDocument creates the result document of the aggregated items, so it is written by the output writers
+// like the result of a run.
+func newAggregatedDocument(inputHeadings []string, ids []string, items map[string]*aggregatedItem, details []codeDetail) (*ResultDocument, error) {
+	headings := slices.Clone(inputHeadings)
+	idColumn := 0
+	layout := appendResultColumns(&headings, details, []string{actionDetermineCommodityCodes})
+
+	rows := inputRows{}
+	results := map[string]*ItemResult{}
+	for _, id := range ids {
+		item := items[id]
+		row := make([]string, len(headings))
+		for i, heading := range inputHeadings {
+			row[i] = item.values[strings.ToLower(strings.TrimSpace(heading))]
+		}
+		row[idColumn] = id
+
+		result := &ItemResult{ID: id, Territories: map[string]*TerritoryResult{}, UpdatedAt: item.updatedAt}
+		for territory, cell := range item.territories {
+			row[layout.resultColumns[territory]] = cell
+			result.Territories[territory] = parseResultCell(territory, cell)
+		}
+		for _, column := range layout.detailColumns {
+			cell, ok := item.details[column.detail.key(column.territory)]
+			territoryResult, hasResult := result.Territories[column.territory]
+			if ok && hasResult {
+				row[column.index] = cell
+				column.detail.parse(territoryResult, cell)
+			}
+		}
+		layout.writeReview(row, result)
+		row[layout.updatedColumn] = formatTime(item.updatedAt)
+		row[layout.warningColumn] = item.warnings
+		if item.warnings != "" {
+			result.Warnings = strings.Split(item.warnings, "; ")
+		}
+		results[id] = result
+
+		rows.add(row, &idColumn)
+	}
+
+	return &ResultDocument{
+		file:         excelize.NewFile(),
+		rows:         rows,
+		written:      true,
+		headings:     headings,
+		idColumn:     idColumn,
+		resultLayout: layout,
+		rowsByID:     indexRows(rows.ids),
+		results:      results,
+	}, nil
+}
+
+// mergeExistingOutput merges the results into the existing output file with the --merge flag, so the output collects
+// the results of several runs. The items of the earlier runs are kept, an item of both gets its latest result.
+func mergeExistingOutput(output OutputConfig, doc *ResultDocument) (*ResultDocument, error) {
+	existing, err := readResultFile(output.Destination)
+	if errors.Is(err, os.ErrNotExist) {
+		return doc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("results can't be merged into the output %q: %w", output.Destination, err)
+	}
+
+	rows, err := doc.Rows()
+	if err != nil {
+		return nil, err
+	}
+	current := resultFile{path: output.Destination, modTime: time.Now(), headings: doc.headings, rows: rows}
+	merged, err := aggregateResults([]resultFile{existing, current})
+	if err != nil {
+		return nil, err
+	}
+	merged.imports = doc.imports
+
+	return merged, nil
+}
+
+// validateMergeOutputs fails if an output file can't be merged with the --merge flag. Only the result files in the xlsx
+// and CSV formats can be read, and they are read by the file extension, which has to match the format of the output.
+func validateMergeOutputs(outputs []OutputConfig) error {
+	extensions := map[string][]string{
+		outputFormatXLSX: {".xlsx", ".xlsm"},
+		outputFormatCSV:  {".csv"},
+	}
+	for _, output := range outputs {
+		if !output.isFile() {
+			continue
+		}
+		formatExtensions, ok := extensions[output.Format]
+		if !ok {
+			return fmt.Errorf("output %q can't be merged, only the xlsx and csv output files can", output.Destination)
+		}
+		if !slices.Contains(formatExtensions, strings.ToLower(filepath.Ext(output.Destination))) {
+			return fmt.Errorf("output %q can't be merged, its file extension doesn't match the %s format", output.Destination, output.Format)
+		}
+	}
+
+	return nil
+}
+
+// resultCodeLengths are the numbers of the digits of the commodity codes of the customs territories, with the shorter
+// levels of --code-level. The codes of the other territories only have to be digits.
+var resultCodeLengths = map[string][]int{
+	customsTerritoryEU: {6, 8, 10},
+	customsTerritoryNO: {6, 8},
+}
+
+// parseResultCell returns the territory result of the result cell, which is either the commodity code or a message.
+// The code is checked against the code lengths of the territory, the spaces and dots of the formatted codes (e.g.
+// "6205 20 00") are ignored. The messages of the unfinished items are told apart from the errors in all languages.
+func parseResultCell(territory, cell string) *TerritoryResult {
+	cell = strings.TrimSpace(cell)
+	code := strings.NewReplacer(" ", "", ".", "").Replace(cell)
+	lengths, known := resultCodeLengths[territory]
+	if code != "" && strings.Trim(code, "0123456789") == "" && (!known || slices.Contains(lengths, len(code))) {
+		return &TerritoryResult{Status: ImportItemStatusProcessed, Code: code}
+	}
+	for status, message := range unfinishedMessages {
+		if cell == message || slices.Contains(translationsOf(message), cell) {
+			return &TerritoryResult{Status: status}
+		}
+	}
+
+	return &TerritoryResult{Status: ImportItemStatusFailed, Error: &cell}
+}
+
+// isOutputFile reports whether the path is one of the output files.
+func isOutputFile(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, output := range outputs {
+		if !output.isFile() {
+			continue
+		}
+		if absOutput, err := filepath.Abs(output.Destination); err == nil && absOutput == absPath {
+			return true
+		}
+	}
+
+	return false
+}
+
+// translationsOf returns the translations of the message in all languages.
+func translationsOf(message string) []string {
+	var translated []string
+	for _, messages := range translations {
+		if t, ok := messages[message]; ok {
+			translated = append(translated, t)
+		}
+	}
+
+	return translated
+}
+
+// getTranslatedColumnIndex returns the index of the column with the heading in any of the languages, because the result
+// files can be written with a different --lang flag.
+func getTranslatedColumnIndex(headings []string, heading string) *int {
+	if i := getColumnIndex(headings, heading); i != nil {
+		return i
+	}
+	for _, messages := range translations {
+		if translated, ok := messages[heading]; ok {
+			if i := getColumnIndex(headings, translated); i != nil {
+				return i
+			}
+		}
+	}
+
+	return nil
+}
+
+func isResultColumn(resultColumns map[string]int, i int) bool {
+	for _, column := range resultColumns {
+		if column == i {
+			return true
+		}
+	}
+
+	return false
+}
+
+func valueOrMinusOne(i *int) int {
+	if i == nil {
+		return -1
+	}
+
+	return *i
+}
@@ -0,0 +1,605 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ItemResult is the outcome of an imported item by the customs territory.
+type ItemResult struct {
+	ID          string                      `json:"id"`
+	Territories map[string]*TerritoryResult `json:"territories"`
+	CreatedAt   time.Time                   `json:"createdAt"`
+	UpdatedAt   time.Time                   `json:"updatedAt"`
+	Warnings    []string                    `json:"warnings,omitempty"`
+
+	ComparedTerritories map[string]*TerritoryResult `json:"comparedTerritories,omitempty"` // of the second model of --compare-models
+	Actions             map[string]*ActionResult    `json:"actions,omitempty"`             // of the --extra-actions by the action name
+}
+
+// TerritoryResult is the outcome of the commodity code determination for a single customs territory.
+type TerritoryResult struct {
+	Status string  `json:"status"`
+	Code   string  `json:"code,omitempty"`
+	Error  *string `json:"error,omitempty"`
+
+	Description string            `json:"description,omitempty"` // nomenclature description of the code
+	DutyRate    string            `json:"dutyRate,omitempty"`
+	VATRate     string            `json:"vatRate,omitempty"`
+	Measures    []MeasureResponse `json:"measures,omitempty"`
+
+	SupplementaryUnit string `json:"supplementaryUnit,omitempty"` // unit of the additional quantity the code requires
+
+	Confidence    *float64 `json:"confidence,omitempty"`    // classification confidence from 0 to 1
+	LowConfidence bool     `json:"lowConfidence,omitempty"` // the code is blanked out, the confidence is below --min-confidence
+
+	Explanation      string   `json:"explanation,omitempty"`
+	NomenclaturePath []string `json:"nomenclaturePath,omitempty"`
+
+	PreviousCode string `json:"previousCode,omitempty"` // code of the earlier run in the input, with --revalidate
+}
+
+// unfinishedMessages are the result cells of the items that are not processed in time or are left to the manual
+// review, by the status.
+var unfinishedMessages = map[string]string{
+	ImportItemStatusProcessing: "Processing didn't finish in time, consider increasing the processing time with --timeout flag",
+	ImportItemStatusPending:    "Processing not started, consider increasing the processing time with --timeout flag. If this error persists, it indicates the server issue, please contact the support.",
+	resultStatusReview:         "Manual review required, the confidence of the code is below the minimum",
+}
+
+// cell returns the text written to the result column of the output workbook.
+func (r TerritoryResult) cell() string {
+	switch r.Status {
+	case ImportItemStatusProcessed:
+		return r.Code
+	case ImportItemStatusProcessing, ImportItemStatusPending, resultStatusReview:
+		return trOutput(unfinishedMessages[r.Status])
+	default:
+		// In the case of error, write the error message.
+		if r.Error != nil {
+			return trOutputf("Error processing item: %q", *r.Error)
+		}
+
+		return trOutput("Error processing item. If this error persists, it indicates the server issue, please contact the support.")
+	}
+}
+
+// streamWriterRows is the number of rows above which the input sheet is written with the stream writer, also to the
+// input workbook. The stream writer links the commodity codes with the HYPERLINK formula instead of the hyperlinks of
+// the sheet.
+const streamWriterRows = 10000
+
+// inputRows are the data rows of the input the results are written to. Of an input file that can be read again, only
+// the item IDs of the rows are held during the run, the rows are read again when the outputs are written.
+type inputRows struct {
+	ids    []string   // item ID of each row
+	values [][]string // rows of the input that can't be read again, e.g. the standard input
+	source rowSource
+}
+
+// add adds the row of the input. The item ID is taken from the values, so the IDs generated with --auto-id and
+// suffixed with --on-duplicate are kept.
+func (r *inputRows) add(values []string, idColumn *int) {
+	r.ids = append(r.ids, getString(values, idColumn))
+	if r.source == nil {
+		r.values = append(r.values, values)
+	}
+}
+
+// ResultDocument is the input workbook with the results of the imports written to it. It is passed to the output
+// writers.
+type ResultDocument struct {
+	file          *excelize.File
+	inputWorkbook bool // the file is the input workbook, not a new one
+	inputColumns  int  // number of the input columns, the appended result columns follow them
+	rows          inputRows
+	written       bool // the rows hold the results already, e.g. the rows of the aggregated results
+	headings      []string
+	idColumn      int
+	resultLayout
+	rowsByID map[string]int // index of the row by the item ID
+	results  map[string]*ItemResult
+	imports  []string                     // locations of the imports the results come from
+	previous map[string]map[string]string // codes of the earlier run by the item ID and the territory, with --revalidate
+	// rowActions is set if the items request their own actions with the actions column, so an item may have no
+	// commodity codes action.
+	rowActions bool
+	streamed   bool // the input sheet is written with the stream writer
+}
+
+// newResultDocument appends the result columns of the actions to the input headings. The results are written to the
+// input workbook if the input is a spreadsheet, otherwise a new workbook is created from the input rows.
+func newResultDocument(reader InputReader, rows inputRows, actions []string) (*ResultDocument, error) {
+	headings := slices.Clone(reader.Headings())
+	idColumn, err := getMandatoryColumnIndex(headings, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	file := excelize.NewFile()
+	workbookReader, inputWorkbook := reader.(interface{ Workbook() *excelize.File })
+	if inputWorkbook {
+		file = workbookReader.Workbook()
+	}
+
+	layout := appendResultColumns(&headings, requestedCodeDetails(), actions)
+
+	doc := &ResultDocument{
+		file:          file,
+		inputWorkbook: inputWorkbook,
+		inputColumns:  len(reader.Headings()),
+		rows:          rows,
+		headings:      headings,
+		idColumn:      idColumn,
+		resultLayout:  layout,
+		rowActions:    getColumnIndex(reader.Headings(), "actions") != nil,
+		rowsByID:      indexRows(rows.ids),
+		results:       map[string]*ItemResult{},
+	}
+	if revalidate {
+		if doc.previous, err = doc.previousCodes(); err != nil {
+			return nil, err
+		}
+		if len(doc.previous) == 0 {
+			warnf("the input has no codes of an earlier run in the result columns, there is nothing to compare the codes with")
+		}
+	}
+
+	return doc, nil
+}
+
+// resultColumn returns the index of the result column in the headings, the column is appended if the headings don't
+// have it yet.
+func resultColumn(headings *[]string, heading string) int {
+	if i := slices.Index(*headings, heading); i >= 0 {
+		return i
+	}
+	*headings = append(*headings, heading)
+
+	return len(*headings) - 1
+}
+
+// add merges the item from an import response into the results, which are written to the item row by the outputs. An
+// item is returned by several imports when the import is split, each of them holding the results for some of the
+// territories.
+func (d *ResultDocument) add(item ImportItemResponse) error {
+	return d.addResults(item, false)
+}
+
+// addComparison merges the item from an import of the second model of --compare-models into the compared results, which
+// are written next to the result columns.
+func (d *ResultDocument) addComparison(item ImportItemResponse) error {
+	return d.addResults(item, true)
+}
+
+func (d *ResultDocument) addResults(item ImportItemResponse, compared bool) error {
+	if _, ok := d.rowsByID[item.ID]; !ok {
+		return fmt.Errorf("error processing import response, row with item id %q is not found", item.ID)
+	}
+
+	// The commodity codes are not requested with e.g. "--action describe".
+	action := item.Action(actionDetermineCommodityCodes)
+	if action == nil && d.classifies() && !d.rowActions {
+		return fmt.Errorf("error processing import response, row with item id %q has no action %q", item.ID, actionDetermineCommodityCodes)
+	}
+	if action != nil {
+		switch action.Status {
+		case ImportItemStatusProcessed, ImportItemStatusProcessing, ImportItemStatusPending, ImportItemStatusFailed:
+		default:
+			return fmt.Errorf("received unexpected action status %q", action.Status)
+		}
+	}
+
+	result, ok := d.results[item.ID]
+	if !ok {
+		result = &ItemResult{
+			ID:          item.ID,
+			Territories: map[string]*TerritoryResult{},
+			CreatedAt:   item.CreatedAt.In(timeLocation),
+		}
+		d.results[item.ID] = result
+	}
+	// The time of the latest update, when the item is returned by several imports.
+	if item.UpdatedAt.After(result.UpdatedAt) {
+		result.UpdatedAt = item.UpdatedAt.In(timeLocation)
+	}
+	for _, warning := range item.Warnings {
+		// The same warning is returned by every import of a split import.
+		if !slices.Contains(result.Warnings, warning.Message) {
+			result.Warnings = append(result.Warnings, warning.Message)
+		}
+	}
+
+	if action != nil {
+		d.addTerritories(result, item, *action, compared)
+	}
+	for action := range d.actionColumns {
+		actionResponse := item.Action(action)
+		if actionResponse == nil || compared {
+			continue
+		}
+		if result.Actions == nil {
+			result.Actions = map[string]*ActionResult{}
+		}
+		result.Actions[action] = &ActionResult{Status: actionResponse.Status, Error: actionResponse.Error, Output: actionResponse.Output}
+	}
+
+	return nil
+}
+
+// writeResults writes the item ID and the results of the item to its row, which has the length of the headings. The
+// IDs generated with --auto-id and suffixed with --on-duplicate are not in the input file.
+func (d *ResultDocument) writeResults(rowIndex int, row []string) {
+	if d.written {
+		return
+	}
+	id := d.rows.ids[rowIndex-1]
+	if id != "" {
+		row[d.idColumn] = id
+	}
+	result, ok := d.results[id]
+	if !ok || d.rowsByID[id] != rowIndex {
+		return
+	}
+
+	for territory, territoryResult := range result.Territories {
+		if i, ok := d.resultColumns[territory]; ok {
+			row[i] = territoryResult.cell()
+		}
+	}
+	for territory, territoryResult := range result.ComparedTerritories {
+		if i, ok := d.modelColumns[territory]; ok {
+			row[i] = territoryResult.cell()
+		}
+	}
+	d.writeDetails(row, result)
+	d.writeActions(row, result)
+	d.writeReview(row, result)
+	d.writeChanges(row, result)
+	d.writeAgreement(row, result)
+	row[d.updatedColumn] = formatTime(result.UpdatedAt)
+	row[d.warningColumn] = strings.Join(result.Warnings, "; ")
+}
+
+// addTerritories merges the commodity codes of the item into the results of the customs territories, or into the
+// compared results of the second model of --compare-models.
+func (d *ResultDocument) addTerritories(result *ItemResult, item ImportItemResponse, action ActionResponse, compared bool) {
+	// The results are kept for the territories requested by the action. If the server doesn't return the action
+	// parameters, the territories of the returned codes are used, and the messages go to the EU column.
+	territories := action.Parameters.CustomsTerritories
+	if len(territories) == 0 {
+		for _, taric := range item.Tarics {
+			territories = append(territories, taric.CustomsTerritory)
+		}
+	}
+	if len(territories) == 0 {
+		territories = []string{customsTerritoryEU}
+	}
+	territoryResults := result.Territories
+	if compared {
+		if result.ComparedTerritories == nil {
+			result.ComparedTerritories = map[string]*TerritoryResult{}
+		}
+		territoryResults = result.ComparedTerritories
+	}
+	for _, territory := range territories {
+		territoryResult := &TerritoryResult{
+			Status: action.Status,
+			Error:  action.Error,
+		}
+		if taric := item.TaricByTerritory(territory); taric != nil {
+			territoryResult.Code = taric.Code
+			territoryResult.Description = taric.Description
+			territoryResult.DutyRate = taric.DutyRate
+			territoryResult.VATRate = taric.VATRate
+			territoryResult.Measures = taric.Measures
+			territoryResult.SupplementaryUnit = taric.SupplementaryUnit
+			territoryResult.Confidence = taric.Confidence
+			territoryResult.Explanation = taric.Explanation
+			territoryResult.NomenclaturePath = taric.NomenclaturePath
+			truncateResult(territoryResult)
+		}
+		// The confidence and the earlier codes apply to the codes of the result columns.
+		if !compared {
+			checkConfidence(result, territory, territoryResult)
+			territoryResult.PreviousCode = d.previous[item.ID][territory]
+		}
+		territoryResults[territory] = territoryResult
+	}
+}
+
+// indexRows returns the indexes of the data rows by the item IDs of the rows, the index of the first data row is 1. The
+// results of an ID that is used by several rows are written to the first of them, the other rows are reported. The
+// rows without an ID hold no item.
+func indexRows(ids []string) map[string]int {
+	rowsByID := make(map[string]int, len(ids))
+	for i, id := range ids {
+		if strings.TrimSpace(id) == "" {
+			continue
+		}
+		if _, ok := rowsByID[id]; ok {
+			warnf("row %d has the duplicate item ID %q, the results are written only to the first row with the ID", i+2, id)
+			continue
+		}
+		rowsByID[id] = i + 1
+	}
+
+	return rowsByID
+}
+
+// Workbook returns the input workbook with the rows of the input sheet, including the result columns, and the hidden
+// metadata sheet written to it. When the input is a small workbook, only the result cells are written to it.
+func (d *ResultDocument) Workbook() (*excelize.File, error) {
+	// A streamed sheet can't be written or read again, the workbook is complete since the first call.
+	if d.streamed {
+		return d.file, nil
+	}
+	// The metadata sheet is written first, hiding it reads the other sheets, which the stream writer leaves unreadable.
+	if err := d.writeMetadata(); err != nil {
+		return nil, err
+	}
+
+	return d.file, d.writeRows()
+}
+
+// writeRows writes the rows of the input sheet with the result columns.
+func (d *ResultDocument) writeRows() error {
+	rowCount := len(d.rows.ids) + 1
+	// Writing the result cells one by one takes too long for a large input sheet, so it is streamed like a new one. The
+	// stream writer can't keep the tables of the sheet, their sheets are always written cell by cell.
+	if rowCount > streamWriterRows {
+		tables, err := d.file.GetTables(inputSheet)
+		if err != nil {
+			return err
+		}
+		if len(tables) == 0 {
+			return d.writeRowsWithStream(rowCount)
+		}
+	}
+
+	var widths []float64
+	err := d.eachRow(func(i int, row []string) error {
+		widths = fitColumnWidths(widths, row)
+		if d.inputWorkbook {
+			return d.writeResultCells(i, row)
+		}
+		// Excel is 1 indexed. The first data row is 2 (the heading is 1).
+		return d.file.SetSheetRow(inputSheet, fmt.Sprintf("A%d", i+1), &row)
+	})
+	if err != nil {
+		return err
+	}
+	if err = d.writeTariffLinks(); err != nil {
+		return err
+	}
+
+	return d.formatSheet(widths, rowCount)
+}
+
+// writeResultCells writes the result columns of the row to the input workbook. Of the other input columns, only the
+// item IDs that were generated or suffixed are written, so the formats, the formulas, the merged cells and the other
+// sheets of the input are kept as they are.
+func (d *ResultDocument) writeResultCells(rowIndex int, row []string) error {
+	for j, value := range row {
+		if j < d.inputColumns && j != d.idColumn && !d.isResultColumn(j) {
+			continue
+		}
+		// Excel is 1 indexed. The first data row is 2 (the heading is 1).
+		cell, err := excelize.CoordinatesToCellName(j+1, rowIndex+1)
+		if err != nil {
+			return err
+		}
+		if j < d.inputColumns {
+			current, err := d.file.GetCellValue(inputSheet, cell)
+			if err != nil {
+				return err
+			}
+			if current == value {
+				continue
+			}
+		}
+		if err = d.file.SetCellStr(inputSheet, cell, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeRowsWithStream replaces the input sheet with the rows using the stream writer, which is much faster and uses less memory
+// than writing the cells of a large sheet one by one. The heading row is frozen and filtered, and the columns fitted,
+// as by formatSheet. Of the input workbook, the values of the input cells are copied without their formats and formulas,
+// while the column widths, the panes, the merged cells and the other sheets are kept.
+func (d *ResultDocument) writeRowsWithStream(rowCount int) error {
+	// The stream writer sets the column widths before the rows, so the rows are read twice.
+	var widths []float64
+	err := d.eachRow(func(_ int, row []string) error {
+		widths = fitColumnWidths(widths, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// The stream writer replaces the sheet, its settings are read before.
+	panes, err := d.file.GetPanes(inputSheet)
+	if err != nil {
+		return err
+	}
+	mergedCells, err := d.file.GetMergeCells(inputSheet)
+	if err != nil {
+		return err
+	}
+	if d.inputWorkbook {
+		for i := 0; i < d.inputColumns && i < len(widths); i++ {
+			column, err := excelize.ColumnNumberToName(i + 1)
+			if err != nil {
+				return err
+			}
+			if widths[i], err = d.file.GetColWidth(inputSheet, column); err != nil {
+				return err
+			}
+		}
+	}
+
+	sw, err := d.file.NewStreamWriter(inputSheet)
+	if err != nil {
+		return err
+	}
+	// The stream writer sets the column widths and the panes before the rows.
+	for i, width := range widths {
+		if err = sw.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+	if !panes.Freeze && panes.XSplit == 0 && panes.YSplit == 0 {
+		if err = sw.SetPanes(&frozenHeading); err != nil {
+			return err
+		}
+	}
+	// The stream writer can't add the hyperlinks to the sheet, the codes are linked with the HYPERLINK formula instead.
+	linkStyle, err := d.newTariffLinkStyle()
+	if err != nil {
+		return err
+	}
+	links := d.tariffLinkCells()
+	err = d.eachRow(func(i int, row []string) error {
+		values := make([]interface{}, len(row))
+		for j, value := range row {
+			values[j] = value
+			if link, ok := links[cellPosition{row: i, column: j}]; ok {
+				values[j] = excelize.Cell{StyleID: linkStyle, Formula: hyperlinkFormula(link, value), Value: value}
+			}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+
+		return sw.SetRow(cell, values)
+	})
+	if err != nil {
+		return err
+	}
+	for _, mergedCell := range mergedCells {
+		if err = sw.MergeCell(mergedCell.GetStartAxis(), mergedCell.GetEndAxis()); err != nil {
+			return err
+		}
+	}
+	// The auto-filter is written with the rows by Flush.
+	if err = d.setAutoFilter(len(widths), rowCount); err != nil {
+		return err
+	}
+
+	d.streamed = true
+
+	return sw.Flush()
+}
+
+// Headings returns the headings of the input sheet with the result columns.
+func (d *ResultDocument) Headings() []string {
+	return d.headings
+}
+
+// eachRow calls the function with the rows of the input sheet with the result columns, starting with the headings row
+// at the index 0. All rows have the length of the headings. The rows of an input file are read from the file again.
+func (d *ResultDocument) eachRow(fn func(i int, row []string) error) error {
+	if err := fn(0, d.headings); err != nil {
+		return err
+	}
+
+	return d.readInputRows(func(i int, values []string) error {
+		row := make([]string, len(d.headings))
+		copy(row, values)
+		d.writeResults(i, row)
+
+		return fn(i, row)
+	})
+}
+
+// readInputRows calls the function with the data rows of the input as they were read, without the results. The index
+// of the first data row is 1.
+func (d *ResultDocument) readInputRows(fn func(i int, values []string) error) (err error) {
+	if d.rows.source == nil {
+		for i, values := range d.rows.values {
+			if err = fn(i+1, values); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	next, closeRows, err := d.rows.source()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := closeRows(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	// The first row holds the headings.
+	if _, err = next(); err != nil {
+		return err
+	}
+	for i := 1; i <= len(d.rows.ids); i++ {
+		values, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(i, values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rows returns the data rows of the input sheet with the result columns. All rows have the length of the headings.
+// The rows are held in memory, the outputs are written with eachRow instead.
+func (d *ResultDocument) Rows() ([][]string, error) {
+	rows := make([][]string, 0, len(d.rows.ids))
+	err := d.eachRow(func(i int, row []string) error {
+		if i > 0 {
+			rows = append(rows, row)
+		}
+		return nil
+	})
+
+	return rows, err
+}
+
+// countWarnings returns the number of items with warnings.
+func (d *ResultDocument) countWarnings() int {
+	count := 0
+	for _, result := range d.results {
+		if len(result.Warnings) > 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Results returns the results of the items in the order of the input rows.
+func (d *ResultDocument) Results() []*ItemResult {
+	results := make([]*ItemResult, 0, len(d.results))
+	for _, result := range d.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return d.rowsByID[results[i].ID] < d.rowsByID[results[j].ID]
+	})
+
+	return results
+}
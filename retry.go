@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// retrier sends the server requests and retries them according to the retry policy. A single retrier is shared by all
+// requests, so the retry budget applies to the whole run.
+type retrier struct {
+	policy RetryConfig
+
+	mu     sync.Mutex
+	budget int
+}
+
+var retries = newRetrier(defaultConfig().Retry)
+
+func newRetrier(policy RetryConfig) *retrier {
+	return &retrier{
+		policy: policy,
+		budget: policy.Budget,
+	}
+}
+
+// Do sends the request. When the attempts or the budget are exhausted, the last response is returned to the caller.
+// Rate limited requests are retried after the time requested by the server, without using the attempts or the budget.
+// A request that is not idempotent is only retried when it certainly didn't reach the server, because after a timeout
+// or a 5xx response the server may have processed it already.
+func (r *retrier) Do(req *http.Request) (*http.Response, error) {
+	rateLimited := 0
+	for attempt := 1; ; {
+		if (attempt > 1 || rateLimited > 0) && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		started := time.Now()
+		res, err := httpClient.Do(req)
+		if err != nil {
+			slog.Debug("request failed", "method", req.Method, "url", req.URL.String(), "attempt", attempt, "duration", time.Since(started), "error", err)
+			retryable := isTransientError(err) && (isIdempotent(req) || isUnsentError(err))
+			if attempt >= r.policy.MaxAttempts || !retryable || !r.take() {
+				return nil, err
+			}
+			if err = sleep(req.Context(), r.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			attempt++
+			continue
+		}
+		slog.Debug("request", "method", req.Method, "url", req.URL.String(), "attempt", attempt, "status", res.StatusCode, "duration", time.Since(started))
+
+		if res.StatusCode == http.StatusTooManyRequests && rateLimited < r.policy.RateLimitRetries {
+			rateLimited++
+			// A bad header mustn't stall the run, the wait is capped like the backoff.
+			wait := min(getRetryAfter(res.Header, r.backoff(rateLimited)), time.Duration(r.policy.Backoff.Max))
+			drainBody(res)
+			fmt.Fprint(console, "\n"+trf("The server is rate limiting the requests, the request %s %s is retried in %s.", req.Method, req.URL.Path, wait)+"\n")
+			if err = sleep(req.Context(), wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		retryable := slices.Contains(r.policy.StatusCodes, res.StatusCode) && isIdempotent(req)
+		if attempt >= r.policy.MaxAttempts || !retryable || !r.take() {
+			if err = checkDeprecation(res); err != nil {
+				_ = res.Body.Close()
+				return nil, err
+			}
+
+			return res, nil
+		}
+		drainBody(res)
+		if err = sleep(req.Context(), r.backoff(attempt)); err != nil {
+			return nil, err
+		}
+		attempt++
+	}
+}
+
+// drainBody reads and closes the response body, so the connection can be reused by the retry.
+func drainBody(res *http.Response) {
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+}
+
+// getRetryAfter returns the wait time from the Retry-After header, which is either a number of seconds or an HTTP date.
+// The fallback is returned if the header is missing or invalid.
+func getRetryAfter(header http.Header, fallback time.Duration) time.Duration {
+	retryAfter := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return fallback
+}
+
+// take uses one retry from the budget. It returns false if the budget is exhausted.
+func (r *retrier) take() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.budget <= 0 {
+		return false
+	}
+	r.budget--
+
+	return true
+}
+
+// backoff returns the wait time after the given attempt. The jitter randomly shortens the wait, so the clients that
+// failed at the same time don't retry at the same time.
+func (r *retrier) backoff(attempt int) time.Duration {
+	backoff := r.policy.Backoff
+	wait := math.Min(float64(backoff.Initial)*math.Pow(backoff.Multiplier, float64(attempt-1)), float64(backoff.Max))
+	wait -= wait * backoff.Jitter * rand.Float64()
+
+	return time.Duration(wait)
+}
+
+// isIdempotent reports whether sending the request twice has the same effect as sending it once: the requests of
+// the methods that don't create anything, and the requests with an idempotency key the server deduplicates by.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// isUnsentError reports whether the request failed before it reached the server, e.g. a refused connection or
+// a failed DNS lookup, so it can be sent again even if it is not idempotent.
+func isUnsentError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isTransientError reports whether the request error is likely to go away on its own, e.g. a reset connection or
+// a failed DNS lookup.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
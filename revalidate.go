@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// previousCodes returns the codes of the result columns the input has from an earlier run, by the item ID and the
+// customs territory, so the codes returned with --revalidate are compared with them. The cells of the failed items are
+// not codes and are left out.
+func (d *ResultDocument) previousCodes() (map[string]map[string]string, error) {
+	previous := map[string]map[string]string{}
+	err := d.readInputRows(func(i int, values []string) error {
+		id := d.rows.ids[i-1]
+		if rowIndex, ok := d.rowsByID[id]; !ok || rowIndex != i {
+			return nil
+		}
+		for territory, column := range d.resultColumns {
+			if column >= d.inputColumns {
+				continue
+			}
+			code := parseResultCell(territory, getCell(values, column)).Code
+			if code == "" {
+				continue
+			}
+			if previous[id] == nil {
+				previous[id] = map[string]string{}
+			}
+			previous[id][territory] = truncateCode(code)
+		}
+
+		return nil
+	})
+
+	return previous, err
+}
+
+// changed reports whether the code of the customs territory differs from the code of the earlier run. The codes are
+// compared only if both runs returned one.
+func (r TerritoryResult) changed() bool {
+	return r.PreviousCode != "" && r.Code != "" && r.Code != r.PreviousCode
+}
+
+// writeChanges writes the changed codes of the item result to the "changed?" column, e.g. "EU: 6205200000 → 6205300000",
+// or "no" if the compared codes are the same.
+func (l resultLayout) writeChanges(row []string, result *ItemResult) {
+	if l.changedColumn < 0 {
+		return
+	}
+	var changes []string
+	compared := false
+	for _, territory := range allowedCustomsTerritories {
+		territoryResult, ok := result.Territories[territory]
+		if !ok || territoryResult.PreviousCode == "" || territoryResult.Code == "" {
+			continue
+		}
+		compared = true
+		if territoryResult.changed() {
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", strings.ToUpper(territory), territoryResult.PreviousCode, territoryResult.Code))
+		}
+	}
+	switch {
+	case len(changes) > 0:
+		row[l.changedColumn] = strings.Join(changes, "; ")
+	case compared:
+		row[l.changedColumn] = trOutput("no")
+	default:
+		row[l.changedColumn] = ""
+	}
+}
+
+// countChanges returns the number of the codes compared with the earlier run and the number of the changed ones.
+func (d *ResultDocument) countChanges() (compared, changed int) {
+	for _, result := range d.results {
+		for _, territoryResult := range result.Territories {
+			if territoryResult.PreviousCode == "" || territoryResult.Code == "" {
+				continue
+			}
+			compared++
+			if territoryResult.changed() {
+				changed++
+			}
+		}
+	}
+
+	return compared, changed
+}
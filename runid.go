@@ -0,0 +1,20 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// runID identifies the run and is sent with every request in the X-Request-ID header, so the run can be found in the
+// server logs.
+var runID = newRunID()
+
+// newRunID returns a random (version 4) UUID.
+func newRunID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultReleasesURL = "https://api.github.com/repos/drotsolutions/customs-cli/releases"
+	// checksumsAsset is the release asset with the SHA-256 checksums of the binaries, in the format of sha256sum.
+	checksumsAsset = "checksums.txt"
+	// signatureAsset is the Ed25519 signature of the checksums asset.
+	signatureAsset = checksumsAsset + ".sig"
+	// selfUpdateTimeout limits the whole update, including the download of the binary.
+	selfUpdateTimeout = 10 * time.Minute
+	// maxAssetSize is the largest release asset that is downloaded.
+	maxAssetSize = 200 * 1024 * 1024
+)
+
+// releaseKey is the base64 encoded Ed25519 public key the checksums of the releases are signed with, set at the build
+// time with -ldflags "-X main.releaseKey=...". Without it, the releases can't be verified and the binary is not updated.
+var releaseKey = ""
+
+type release struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+func runSelfUpdate(args []string) {
+	var check bool
+	var tag, releasesURL string
+	fs := newCommandFlagSet("self-update")
+	fs.BoolVar(&check, "check", false, "")
+	fs.StringVar(&tag, "release", "", "")
+	fs.StringVar(&releasesURL, "releases-url", defaultReleasesURL, "")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Update the binary to the latest release from GitHub. The binary of the platform is downloaded, verified by
+	its checksum and the signature of the checksums, and replaces the running binary. The binaries built without the
+	release key, e.g. with "go install", can only check for a newer release.
+
+	Options:
+		--check			only print whether a newer release is available, exit code 1 if it is
+		--release		tag of the release to install instead of the latest one, e.g. v1.4.0
+		--releases-url		URL of the releases API, for a mirror of the releases (default %q)
+		--yes			update without the confirmation
+		--proxy, --cacert	proxy and the CA certificate for the download, the same as in "customs --help", --insecure is
+					not accepted
+		--help			display this help and exit
+
+	Example:
+		customs self-update
+		customs self-update --check
+
+`, defaultReleasesURL)
+
+		os.Exit(0)
+	}
+
+	// The signature protects the binary, but not the release the update picks, so the certificates are always verified.
+	if insecure {
+		fatal("insecure flag can't be used with the self-update command")
+	}
+	clientOptions := httpClientOptions{requestTimeout: selfUpdateTimeout, caCert: caCert}
+	if proxy != "" {
+		var err error
+		clientOptions.proxy, err = parseProxy(proxy)
+		if err != nil {
+			fatal(err)
+		}
+	}
+	client, err := newHTTPClient(clientOptions)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, selfUpdateTimeout)
+	defer cancel()
+
+	r, err := getRelease(ctx, client, releasesURL, tag)
+	if err != nil {
+		fatal(err)
+	}
+	cmp, comparable := compareVersions(version, r.TagName)
+	if check {
+		switch {
+		case !comparable:
+			fmt.Println(trf("The latest release is %s, the version of this build (%s) can't be compared.", r.TagName, version))
+		case cmp < 0:
+			fmt.Println(trf("A newer release %s is available, the installed version is %s, run \"customs self-update\" to update.", r.TagName, version))
+			os.Exit(1)
+		default:
+			fmt.Println(trf("The installed version %s is up to date.", version))
+		}
+		return
+	}
+	// A release given by its tag is installed even if it is older, e.g. to go back to the previous release.
+	if comparable && (cmp == 0 || cmp > 0 && tag == "") {
+		fmt.Println(trf("The installed version %s is up to date.", version))
+		return
+	}
+
+	if releaseKey == "" {
+		fatal("the binary is built without the release key, the release can't be verified and is not installed, please download it manually")
+	}
+	if err = confirm(trf("Update the CLI from %s to %s?", version, r.TagName)); err != nil {
+		fatal(err)
+	}
+	if err = installRelease(ctx, client, r); err != nil {
+		fatal(err)
+	}
+	fmt.Println(trf("The CLI has been updated to %s.", r.TagName))
+}
+
+// getRelease returns the release with the tag, or the latest release without it.
+func getRelease(ctx context.Context, client *http.Client, releasesURL, tag string) (*release, error) {
+	endpoint := strings.TrimSuffix(releasesURL, "/") + "/latest"
+	if tag != "" {
+		endpoint = strings.TrimSuffix(releasesURL, "/") + "/tags/" + tag
+	}
+	body, err := download(ctx, client, endpoint, "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("the release can't be found: %w", err)
+	}
+
+	var r release
+	if err = json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("invalid release: %w", err)
+	}
+	if r.TagName == "" {
+		return nil, fmt.Errorf("invalid release: missing tag name")
+	}
+
+	return &r, nil
+}
+
+// binaryAsset returns the name of the release asset with the binary of the platform, e.g. "customs_linux_amd64".
+func binaryAsset() string {
+	name := fmt.Sprintf("customs_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	return name
+}
+
+// installRelease downloads the binary of the platform from the release, verifies it and replaces the running binary
+// with it.
+func installRelease(ctx context.Context, client *http.Client, r *release) error {
+	assets := map[string]string{}
+	for _, asset := range r.Assets {
+		assets[asset.Name] = asset.BrowserDownloadURL
+	}
+	name := binaryAsset()
+	if assets[name] == "" {
+		return fmt.Errorf("release %s has no binary for %s/%s", r.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	if assets[checksumsAsset] == "" {
+		return fmt.Errorf("release %s has no checksums, the binary can't be verified", r.TagName)
+	}
+	if assets[signatureAsset] == "" {
+		return fmt.Errorf("release %s has no signature of the checksums, the binary can't be verified", r.TagName)
+	}
+
+	checksums, err := download(ctx, client, assets[checksumsAsset], "")
+	if err != nil {
+		return err
+	}
+	signature, err := download(ctx, client, assets[signatureAsset], "")
+	if err != nil {
+		return err
+	}
+	if err = verifySignature(checksums, signature); err != nil {
+		return err
+	}
+	checksum, err := findChecksum(checksums, name)
+	if err != nil {
+		return err
+	}
+
+	binary, err := download(ctx, client, assets[name], "")
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(binary)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return fmt.Errorf("checksum of the downloaded binary %s doesn't match the checksum of the release", name)
+	}
+
+	return replaceExecutable(binary)
+}
+
+// verifySignature verifies the signature of the checksums with the release key. The signature is either raw or base64
+// encoded.
+func verifySignature(checksums, signature []byte) error {
+	key, err := base64.StdEncoding.DecodeString(releaseKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release key of the build")
+	}
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+		if err != nil {
+			return fmt.Errorf("invalid signature of the checksums")
+		}
+		signature = decoded
+	}
+	if !ed25519.Verify(key, checksums, signature) {
+		return fmt.Errorf("signature of the checksums is not valid, the release is not installed")
+	}
+
+	return nil
+}
+
+// findChecksum returns the checksum of the asset from the checksums, one "<checksum>  <name>" line per asset.
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// The binary mode of sha256sum marks the name with an asterisk.
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", fmt.Errorf("checksums of the release have no checksum of %s", name)
+}
+
+// replaceExecutable replaces the running binary with the new one. The new binary is written next to it first, so the
+// binary is replaced by a rename and is never left half written.
+func replaceExecutable(binary []byte) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(executable)
+	file, err := os.CreateTemp(dir, ".customs-update-")
+	if err != nil {
+		return fmt.Errorf("the binary in %s can't be replaced: %w", dir, err)
+	}
+	_, err = file.Write(binary)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), stat.Mode().Perm()|0o111)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	// Windows doesn't allow to replace the running binary, but it allows to rename it.
+	old := executable + ".old"
+	_ = os.Remove(old)
+	if err = os.Rename(executable, old); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err = os.Rename(file.Name(), executable); err != nil {
+		_ = os.Rename(old, executable)
+		_ = os.Remove(file.Name())
+		return err
+	}
+	// The old binary can't be removed while it is running on Windows, it is removed by the next update.
+	_ = os.Remove(old)
+
+	return nil
+}
+
+// download returns the body of the source URL.
+func download(ctx context.Context, client *http.Client, source, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	req.Header.Set("User-Agent", userAgent())
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code while downloading %s %d", source, res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxAssetSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", source, maxAssetSize)
+	}
+
+	return body, nil
+}
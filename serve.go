@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultListen = "localhost:8080"
+	// maxUploadSize is the largest input file the server accepts.
+	maxUploadSize = 100 * 1024 * 1024
+	// jobRetention is how long the results of a finished job can be downloaded.
+	jobRetention = 24 * time.Hour
+)
+
+const (
+	jobStatusQueued     = "queued"
+	jobStatusProcessing = "processing"
+	jobStatusDone       = "done"
+	jobStatusFailed     = "failed"
+)
+
+// uploadExtensions are the input file extensions by the content type of the upload without a file name.
+var uploadExtensions = map[string]string{
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": ".xlsx",
+	"text/csv":         ".csv",
+	"application/json": ".json",
+}
+
+// job is an uploaded input file imported by the server. Its results are written to the files of all output formats
+// in the job directory.
+type job struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Input      string     `json:"input"`
+	Items      int        `json:"items,omitempty"`
+	Warnings   int        `json:"warnings,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
+	dir string
+}
+
+// jobServer is the REST API of the serve command. The jobs are imported one at a time in the order of the upload.
+type jobServer struct {
+	dir   string
+	token string // required in the Authorization header of every request if set
+	mu    sync.Mutex
+	jobs  map[string]*job
+	// queue holds the jobs waiting for the import.
+	queue chan *job
+}
+
+func runServe(args []string) {
+	var listen, token string
+	fs := newCommandFlagSet("serve")
+	fs.StringVar(&listen, "listen", defaultListen, "")
+	fs.StringVar(&token, "token", "", "")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Serve a REST API that imports the uploaded input files, so the internal tools can use the import without running the binary.
+
+	Endpoints:
+		POST /jobs			upload the input file (the request body, or the "file" field of a multipart form) and get the job ID,
+						the format is taken from the "name" query parameter, the file name or the content type
+		GET /jobs/{id}			status of the job: queued, processing, done or failed
+		GET /jobs/{id}/result		download the result file, "?format=csv" or "?format=json" for the other formats (default xlsx)
+
+	Options:
+		--listen	address the server listens on (default %q)
+		--token		token the requests must send in the "Authorization: Bearer <token>" header, required unless the server
+				listens on a loopback address only
+		--help		display this help and exit
+
+	The documents and the description files referenced by the uploaded file are read only from the directory of the
+	upload, so a job can't read the other files of the server.
+
+	The other options of the import are the same as in "customs --help".
+
+	Example:
+		customs serve --api-key "yourApiKey" --listen localhost:8080 --token "yourToken"
+		curl -H "Authorization: Bearer yourToken" -F file=@input-file.xlsx http://localhost:8080/jobs
+
+`, defaultListen)
+
+		os.Exit(0)
+	}
+
+	config := setupRun()
+
+	// Listen before anything is created, so an address in use fails the command right away.
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		fatal(err)
+	}
+	if token == "" && !isLoopback(listener.Addr()) {
+		fatalf("token flag is required when the server listens on %s, which is not a loopback address", listener.Addr())
+	}
+	dir, err := os.MkdirTemp("", "customs-serve-")
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	s := &jobServer{dir: dir, token: token, jobs: map[string]*job{}, queue: make(chan *job, 1000)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	processed := make(chan struct{})
+	go func() {
+		s.process(ctx, config.Outputs)
+		close(processed)
+	}()
+
+	server := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	shutdown := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+		close(shutdown)
+	}()
+
+	fmt.Fprintln(console, trf("Listening on %s, press Ctrl+C to stop.", listen))
+	if err = server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatal(err)
+	}
+	// The job being imported is interrupted together with the server, the queued jobs are not started.
+	<-shutdown
+	close(s.queue)
+	<-processed
+}
+
+// isLoopback reports whether the server listens only on the loopback interface, e.g. on localhost.
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+
+	return ok && tcpAddr.IP.IsLoopback()
+}
+
+func (s *jobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "missing or invalid token in the Authorization header")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "jobs" && r.Method == http.MethodPost:
+		s.createJob(w, r)
+	case len(parts) == 2 && parts[0] == "jobs" && r.Method == http.MethodGet:
+		s.getJob(w, parts[1])
+	case len(parts) == 3 && parts[0] == "jobs" && parts[2] == "result" && r.Method == http.MethodGet:
+		s.getResult(w, r, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// authorized reports whether the request has the token of the server, or the server has no token.
+func (s *jobServer) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// createJob saves the uploaded input file and queues its import.
+func (s *jobServer) createJob(w http.ResponseWriter, r *http.Request) {
+	s.removeExpiredJobs()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	body, name, err := readUpload(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	if _, ok := inputExtensions[strings.ToLower(filepath.Ext(name))]; !ok {
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("format of the input %q is not supported", name))
+		return
+	}
+
+	j := &job{ID: newRunID(), Status: jobStatusQueued, Input: name, CreatedAt: time.Now()}
+	j.dir = filepath.Join(s.dir, j.ID)
+	if err = os.Mkdir(j.dir, 0o700); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	file, err := os.Create(filepath.Join(j.dir, name))
+	if err == nil {
+		_, err = io.Copy(file, body)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(j.dir)
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("upload failed: %s", err))
+		return
+	}
+
+	s.mu.Lock()
+	s.jobs[j.ID] = j
+	s.mu.Unlock()
+	select {
+	case s.queue <- j:
+	default:
+		s.finish(j, inputSummary{err: fmt.Errorf("too many jobs are waiting, try again later")})
+	}
+
+	w.Header().Set("Location", "/jobs/"+j.ID)
+	writeJSON(w, http.StatusAccepted, s.snapshot(j))
+}
+
+// readUpload returns the uploaded file and its name. The file is either the "file" field of a multipart form, or the
+// request body with the name from the "name" query parameter or the content type.
+func readUpload(r *http.Request) (io.ReadCloser, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("missing %q field of the form: %w", "file", err)
+		}
+
+		return file, filepath.Base(header.Filename), nil
+	}
+
+	name := filepath.Base(r.URL.Query().Get("name"))
+	if name == "." || name == "/" {
+		extension, ok := uploadExtensions[mediaType]
+		if !ok {
+			return nil, "", fmt.Errorf("please provide the file name in the %q query parameter, e.g. ?name=items.csv", "name")
+		}
+		name = "input" + extension
+	}
+
+	return r.Body, name, nil
+}
+
+func (s *jobServer) getJob(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("job %q is not found", id))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.snapshot(j))
+}
+
+func (s *jobServer) getResult(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	var status string
+	if ok {
+		status = j.Status
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("job %q is not found", id))
+		return
+	}
+	if status != jobStatusDone {
+		writeError(w, http.StatusConflict, fmt.Sprintf("job %q is %s, the result is available once it is done", id, status))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = outputFormatXLSX
+	}
+	writer, ok := outputWriters[format].(EncodingOutputWriter)
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("output format %q is not supported", format))
+		return
+	}
+
+	name := strings.TrimSuffix(j.Input, filepath.Ext(j.Input)) + "-result." + format
+	w.Header().Set("Content-Type", writer.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	http.ServeFile(w, r, filepath.Join(j.dir, "result."+format))
+}
+
+// process imports the queued jobs one after another, until the queue is closed. The jobs queued after the context is
+// canceled fail.
+func (s *jobServer) process(ctx context.Context, configOutputs []OutputConfig) {
+	for j := range s.queue {
+		if ctx.Err() != nil {
+			s.finish(j, inputSummary{err: fmt.Errorf("the server is stopped")})
+			continue
+		}
+		s.mu.Lock()
+		j.Status = jobStatusProcessing
+		s.mu.Unlock()
+
+		input := filepath.Join(j.dir, j.Input)
+		jobOutputs := inputOutputs(configOutputs, input)
+		for _, format := range []string{outputFormatXLSX, outputFormatCSV, outputFormatJSON} {
+			jobOutputs = append(jobOutputs, OutputConfig{Format: format, Destination: filepath.Join(j.dir, "result."+format)})
+		}
+		summary, err := importInput(input, jobOutputs, importOptions{several: true, job: true})
+		if err != nil {
+			fmt.Fprintln(console, trf("The input %q failed: %s", j.Input, err))
+		}
+		s.finish(j, summary)
+	}
+}
+
+func (s *jobServer) finish(j *job, summary inputSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j.Status = jobStatusDone
+	j.Items = summary.items
+	j.Warnings = summary.warnings
+	if summary.err != nil {
+		j.Status = jobStatusFailed
+		j.Error = summary.err.Error()
+	}
+	finishedAt := time.Now()
+	j.FinishedAt = &finishedAt
+}
+
+// snapshot returns a copy of the job, so it can be encoded while the job is processed.
+func (s *jobServer) snapshot(j *job) job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *j
+}
+
+// removeExpiredJobs removes the jobs finished longer than the job retention ago, together with their files.
+func (s *jobServer) removeExpiredJobs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, j := range s.jobs {
+		if j.FinishedAt != nil && time.Since(*j.FinishedAt) > jobRetention {
+			_ = os.RemoveAll(j.dir)
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
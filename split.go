@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+const (
+	splitImportByTerritory = "territory"
+)
+
+// planImports returns the imports the items are sent in: split by the customs territories with --split-import-by,
+// anonymized, in chunks and within the request size limit. With --compare-models, the imports of the second model
+// follow the imports of the first one, whose number is returned too.
+func planImports(imp ImportRequest, anonymous *anonymizedIDs) ([]ImportRequest, int, error) {
+	if len(comparedModels) > 0 {
+		imp = withModel([]ImportRequest{imp}, comparedModels[0])[0]
+	}
+	imports := []ImportRequest{imp}
+	if splitImportBy == splitImportByTerritory {
+		imports = splitImportByTerritories(imp)
+	}
+	// The imports are anonymized once they are split by the territories, before the chunks and the sizes, which
+	// depend on the sent IDs.
+	for i := range imports {
+		imports[i] = anonymous.request(imports[i])
+	}
+	if chunkSize > 0 {
+		imports = splitImportByChunks(imports, chunkSize)
+	}
+	imports, err := splitImportBySize(imports, maxRequestSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	firstModelImports := len(imports)
+	if len(comparedModels) > 0 {
+		imports = append(imports, comparedImports(imports, comparedModels[1])...)
+	}
+
+	return imports, firstModelImports, nil
+}
+
+// splitImportByTerritories splits the import into one import per customs territory. An item requested for several
+// territories is sent in each of the territory imports, with its classification narrowed down to that territory. The
+// other actions, e.g. the description, don't depend on the territory, so they are sent only with the first import of
+// the item instead of being run and charged once per territory.
+func splitImportByTerritories(imp ImportRequest) []ImportRequest {
+	var imports []ImportRequest
+	attached := map[[2]int]bool{} // the other actions already in an import, by the indexes of the item and the action
+	for _, territory := range allowedCustomsTerritories {
+		var territoryImport ImportRequest
+		for i, item := range imp.ImportItems {
+			var actions []ActionRequest
+			for j, action := range item.Actions {
+				if !slices.Contains(action.Parameters.CustomsTerritories, territory) {
+					continue
+				}
+				if action.Name != actionDetermineCommodityCodes {
+					if !attached[[2]int{i, j}] {
+						attached[[2]int{i, j}] = true
+						actions = append(actions, action)
+					}
+					continue
+				}
+				action.Parameters.CustomsTerritories = []string{territory}
+				actions = append(actions, action)
+			}
+			if len(actions) == 0 {
+				continue
+			}
+			item.Actions = actions
+			territoryImport.ImportItems = append(territoryImport.ImportItems, item)
+		}
+		if len(territoryImport.ImportItems) > 0 {
+			imports = append(imports, territoryImport)
+		}
+	}
+
+	return imports
+}
+
+// splitImportByChunks splits the imports with more than size items into imports of at most size items, so a large
+// input doesn't exceed the size of the import request the server accepts.
+func splitImportByChunks(imports []ImportRequest, size int) []ImportRequest {
+	var chunks []ImportRequest
+	for _, imp := range imports {
+		for start := 0; start < len(imp.ImportItems); start += size {
+			end := min(start+size, len(imp.ImportItems))
+			chunks = append(chunks, ImportRequest{ImportItems: imp.ImportItems[start:end]})
+		}
+	}
+
+	return chunks
+}
+
+// splitImportBySize splits the imports whose request body is larger than the limit in halves, until every import fits
+// in the limit. It fails if a single item doesn't fit.
+func splitImportBySize(imports []ImportRequest, limit int) ([]ImportRequest, error) {
+	var result []ImportRequest
+	for _, imp := range imports {
+		size, err := requestSize(imp)
+		if err != nil {
+			return nil, err
+		}
+		if size <= limit {
+			result = append(result, imp)
+			continue
+		}
+		if len(imp.ImportItems) == 1 {
+			return nil, fmt.Errorf("item %q is %s in the import request, larger than the request size limit of %s", imp.ImportItems[0].ID, formatSize(size), formatSize(limit))
+		}
+
+		half := len(imp.ImportItems) / 2
+		parts, err := splitImportBySize([]ImportRequest{
+			{ImportItems: imp.ImportItems[:half]},
+			{ImportItems: imp.ImportItems[half:]},
+		}, limit)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, parts...)
+	}
+
+	return result, nil
+}
+
+// requestSize returns the size of the uncompressed import request body.
+func requestSize(imp ImportRequest) (int, error) {
+	body, err := json.Marshal(imp)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(body), nil
+}
+
+func formatSize(size int) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KiB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
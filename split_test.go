@@ -0,0 +1,40 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitImportByTerritories(t *testing.T) {
+	both := []string{customsTerritoryEU, customsTerritoryNO}
+	imp := ImportRequest{ImportItems: []ImportItemRequest{
+		{ID: "1", Actions: []ActionRequest{
+			{Name: actionDetermineCommodityCodes, Parameters: Parameters{CustomsTerritories: both}},
+			{Name: actionEnrichDescription, Parameters: Parameters{CustomsTerritories: both}},
+		}},
+		{ID: "2", Actions: []ActionRequest{
+			{Name: actionDetermineCommodityCodes, Parameters: Parameters{CustomsTerritories: []string{customsTerritoryNO}}},
+			{Name: actionDetermineOrigin, Parameters: Parameters{CustomsTerritories: []string{customsTerritoryNO}}},
+		}},
+	}}
+
+	// The actions of the items in the imports, e.g. "1 determineCommodityCodes eu".
+	var got [][]string
+	for _, territoryImport := range splitImportByTerritories(imp) {
+		var actions []string
+		for _, item := range territoryImport.ImportItems {
+			for _, action := range item.Actions {
+				actions = append(actions, item.ID+" "+action.Name+" "+action.Parameters.CustomsTerritories[0])
+			}
+		}
+		got = append(got, actions)
+	}
+
+	want := [][]string{
+		{"1 determineCommodityCodes eu", "1 enrichDescription eu"},
+		{"1 determineCommodityCodes no", "2 determineCommodityCodes no", "2 determineOrigin no"},
+	}
+	if !slices.EqualFunc(got, want, slices.Equal[[]string]) {
+		t.Errorf("splitImportByTerritories() actions = %q, want %q", got, want)
+	}
+}
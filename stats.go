@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultMinDescriptionLength = 30
+)
+
+// catalogStats are the data quality metrics of the input, the signals that best predict the failed classifications.
+type catalogStats struct {
+	items              int
+	invalidItems       []string
+	shortDescriptions  int
+	missingOrigins     int
+	missingMasses      int
+	missingCategories  int
+	territoryItemCount map[string]int
+}
+
+func runStats(args []string) {
+	var minDescriptionLength int
+	fs := newCommandFlagSet("stats")
+	fs.IntVar(&minDescriptionLength, "min-description-length", defaultMinDescriptionLength, "")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Report the data quality metrics of the input file without importing it.
+
+	Options:
+		--min-description-length	descriptions shorter than this number of characters are reported as short (default %d)
+		--help		display this help and exit
+
+	Example:
+		customs stats input-file.xlsx
+
+`, defaultMinDescriptionLength)
+
+		os.Exit(0)
+	}
+
+	filePath := fs.Arg(0)
+	if filePath == "" {
+		fatal("please provide the input file path as the command argument")
+	}
+	setupColumnAliases()
+	reader, err := openInput(filePath)
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		// Close the input.
+		if err = reader.Close(); err != nil {
+			fatal(err)
+		}
+	}()
+
+	stats := catalogStats{territoryItemCount: map[string]int{}}
+	for {
+		input, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var itemErr *ItemError
+		if errors.As(err, &itemErr) {
+			// The invalid items are part of the report, the reading continues with the next item.
+			stats.items++
+			stats.invalidItems = append(stats.invalidItems, itemErr.Error())
+			continue
+		}
+		if err != nil {
+			fatal(err)
+		}
+		if input.Skipped {
+			continue
+		}
+
+		stats.add(input.Item, minDescriptionLength)
+	}
+
+	stats.print(minDescriptionLength)
+}
+
+func (s *catalogStats) add(item ImportItemRequest, minDescriptionLength int) {
+	s.items++
+	if utf8.RuneCountInString(strings.TrimSpace(item.Description)) < minDescriptionLength {
+		s.shortDescriptions++
+	}
+	if isBlank(item.CountryOfOrigin) {
+		s.missingOrigins++
+	}
+	if item.GrossMass == nil && item.NetMass == nil {
+		s.missingMasses++
+	}
+	if isBlank(item.Category) {
+		s.missingCategories++
+	}
+	for _, action := range item.Actions {
+		for _, territory := range action.Parameters.CustomsTerritories {
+			s.territoryItemCount[territory]++
+		}
+	}
+}
+
+func (s *catalogStats) print(minDescriptionLength int) {
+	fmt.Println(trf("Items: %d", s.items))
+	if s.items == 0 {
+		return
+	}
+
+	printStat := func(label string, count int) {
+		fmt.Printf("  %-45s %6d %6.1f%%\n", label, count, 100*float64(count)/float64(s.items))
+	}
+	printStat(tr("Invalid items"), len(s.invalidItems))
+	printStat(trf("Descriptions under %d characters", minDescriptionLength), s.shortDescriptions)
+	printStat(tr("Missing country of origin"), s.missingOrigins)
+	printStat(tr("Missing gross and net mass"), s.missingMasses)
+	printStat(tr("Missing category"), s.missingCategories)
+
+	fmt.Println(tr("Items by customs territory:"))
+	for _, territory := range allowedCustomsTerritories {
+		printStat(strings.ToUpper(territory), s.territoryItemCount[territory])
+	}
+
+	if len(s.invalidItems) > 0 {
+		fmt.Println(tr("Invalid items:"))
+		for _, invalidItem := range s.invalidItems {
+			fmt.Printf("  %s\n", invalidItem)
+		}
+	}
+}
+
+func isBlank(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
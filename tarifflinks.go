@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// maxHyperlinks is the number of the hyperlinks Excel allows in a worksheet, the codes above it are written without
+// the link.
+const maxHyperlinks = 65530
+
+// tariffLinks are the pages of the official tariff browsers the commodity codes of the xlsx outputs link to, by the
+// customs territory. The "%s" is replaced by the code. The links can be changed with "tariffLinks" of the config file.
+var tariffLinks = map[string]string{
+	customsTerritoryEU: "https://ec.europa.eu/taxation_customs/dds2/taric/measures.jsp?Lang=en&Taric=%s",
+	customsTerritoryNO: "https://tolltariffen.toll.no/tolltariff/search?query=%s",
+}
+
+// cellPosition is the 0 indexed row and column of a cell of the input sheet, the row index includes the heading row.
+type cellPosition struct {
+	row, column int
+}
+
+// writeTariffLinks links the commodity codes of the result columns to the tariff browser of their customs territory,
+// so the reviewers can verify a classification with one click.
+func (d *ResultDocument) writeTariffLinks() error {
+	style, err := d.newTariffLinkStyle()
+	if err != nil {
+		return err
+	}
+
+	for position, link := range d.tariffLinkCells() {
+		// Excel is 1 indexed.
+		cell, err := excelize.CoordinatesToCellName(position.column+1, position.row+1)
+		if err != nil {
+			return err
+		}
+		if err = d.file.SetCellHyperLink(inputSheet, cell, link, "External"); err != nil {
+			return err
+		}
+		if err = d.file.SetCellStyle(inputSheet, cell, cell, style); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newTariffLinkStyle returns the style of the linked commodity codes.
+func (d *ResultDocument) newTariffLinkStyle() (int, error) {
+	return d.file.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "0563C1", Underline: "single"}})
+}
+
+// tariffLinkCells returns the links of the result cells with a commodity code, by the position of the cell.
+func (d *ResultDocument) tariffLinkCells() map[cellPosition]string {
+	cells := make(map[cellPosition]string)
+	for id, result := range d.results {
+		rowIndex, ok := d.rowsByID[id]
+		if !ok {
+			continue
+		}
+		for territory, territoryResult := range result.Territories {
+			link, ok := tariffLinks[territory]
+			column, hasColumn := d.resultColumns[territory]
+			if !ok || !hasColumn || territoryResult.Status != ImportItemStatusProcessed || territoryResult.Code == "" {
+				continue
+			}
+			if len(cells) == maxHyperlinks {
+				return cells
+			}
+			cells[cellPosition{row: rowIndex, column: column}] = fmt.Sprintf(link, territoryResult.Code)
+		}
+	}
+
+	return cells
+}
+
+// hyperlinkFormula returns the HYPERLINK formula of the link, which the stream writer writes instead of the hyperlinks
+// of the sheet.
+func hyperlinkFormula(link, text string) string {
+	quote := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+
+	return "HYPERLINK(" + quote(link) + "," + quote(text) + ")"
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// defaultCustomsTerritories are the customs territories of the items when the input has no customs territories column.
+var defaultCustomsTerritories []string
+
+// resolveCustomsTerritories returns the customs territories of the items for the input without the customs territories
+// column. If the API key is scoped to a single territory, the items are imported for it, otherwise the operator is
+// asked for the territories.
+func resolveCustomsTerritories(ctx context.Context, url, apiKey string) ([]string, error) {
+	territories, err := accountCustomsTerritories(ctx, url, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(territories) == 1 {
+		fmt.Fprintln(console, trf("The input has no customs territories column, the items are imported for %s, the only customs territory of the account.", strings.ToUpper(territories[0])))
+		return prepareCustomsTerritories(territories[0])
+	}
+
+	answer, err := ask(trf("The input has no customs territories column. Customs territories of the items (%s):", strings.Join(territories, ", ")))
+	if err != nil || answer == "" {
+		return nil, fmt.Errorf(`provided file has no %q column`, "customs territories")
+	}
+
+	return prepareCustomsTerritories(answer)
+}
+
+// jobCustomsTerritories returns the customs territories of the items of a serve job without the customs territories
+// column. Nobody can be asked for them, so the job is imported only for the single territory of the account.
+func jobCustomsTerritories(ctx context.Context, url, apiKey string) ([]string, error) {
+	territories, err := accountCustomsTerritories(ctx, url, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(territories) != 1 {
+		return nil, fmt.Errorf("provided file has no %q column, it is required for the account with the customs territories %s", "customs territories", strings.Join(territories, ", "))
+	}
+
+	return prepareCustomsTerritories(territories[0])
+}
+
+// accountCustomsTerritories returns the customs territories of the account, or all territories if the account doesn't
+// list them.
+func accountCustomsTerritories(ctx context.Context, url, apiKey string) ([]string, error) {
+	account, err := getAccount(ctx, url, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("the customs territories of the account can't be fetched: %w", err)
+	}
+	if len(account.CustomsTerritories) > 0 {
+		return account.CustomsTerritories, nil
+	}
+
+	return allowedCustomsTerritories, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeLayout is the layout of the timestamps in the spreadsheet outputs. The zone is part of it, so the times around
+// midnight aren't misread.
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+// runStartedAt is the time the run was started.
+var runStartedAt = time.Now()
+
+// timeLocation is the time zone the timestamps of the outputs and the log messages are rendered in. The process-wide
+// time.Local is left alone, so the zone doesn't leak into the libraries and the other jobs of the process.
+var timeLocation = time.Local
+
+// setTimeZone sets the time zone all timestamps are rendered in, including the timestamps of the log messages. The zone
+// is the IANA name (e.g. "Europe/Oslo"), "UTC" or "Local".
+func setTimeZone(name string) error {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("time zone %q is not supported: %w", name, err)
+	}
+	timeLocation = location
+
+	return nil
+}
+
+// formatTime returns the timestamp in the selected time zone, or an empty string for the zero time.
+func formatTime(t time.Time) string {
+	return formatTimeIn(t, timeLocation)
+}
+
+// formatTimeIn returns the timestamp in the time zone, or an empty string for the zero time.
+func formatTimeIn(t time.Time, location *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.In(location).Format(timeLayout)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// traceBodyLimit is the number of bytes of the request and response bodies in the trace.
+const traceBodyLimit = 2048
+
+const redacted = "[redacted]"
+
+// tracingTransport logs the metadata and the beginning of the body of every request and response, enabled with the
+// --debug flag. The credentials are redacted, so the log can be sent to the support.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func newTracingTransport(next http.RoundTripper) *tracingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &tracingTransport{next: next}
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("HTTP request",
+		"method", req.Method,
+		"url", redact(req.URL.String()),
+		"headers", traceHeaders(req.Header),
+		"body", traceBody(body, req.Header.Get("Content-Encoding")))
+
+	started := time.Now()
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		slog.Debug("HTTP request failed", "method", req.Method, "url", redact(req.URL.String()), "duration", time.Since(started), "error", err)
+		return nil, err
+	}
+
+	body, err = io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("HTTP response",
+		"method", req.Method,
+		"url", redact(req.URL.String()),
+		"status", res.StatusCode,
+		"duration", time.Since(started),
+		"headers", traceHeaders(res.Header),
+		"body", traceBody(body, res.Header.Get("Content-Encoding")))
+
+	return res, nil
+}
+
+// requestBody returns the body of the request without consuming it.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = body.Close()
+		}()
+
+		return io.ReadAll(body)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// traceHeaders returns the headers sorted by the name, with the values of the credential headers redacted.
+func traceHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		value := strings.Join(header[name], "; ")
+		if isCredentialHeader(name) {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s: %s", name, redact(value))
+	}
+
+	return b.String()
+}
+
+// isCredentialHeader reports whether the header carries credentials, e.g. the API key or the headers of the webhook
+// outputs with a token.
+func isCredentialHeader(name string) bool {
+	name = strings.ToLower(name)
+	if name == "authorization" || name == "proxy-authorization" || name == "cookie" || name == "set-cookie" {
+		return true
+	}
+
+	return strings.Contains(name, "key") || strings.Contains(name, "token") || strings.Contains(name, "secret")
+}
+
+// traceBody returns the beginning of the body, decompressed if it is compressed with gzip.
+func traceBody(body []byte, encoding string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if strings.EqualFold(encoding, "gzip") {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return fmt.Sprintf("<%d bytes compressed with gzip>", len(body))
+		}
+		// The decompressed body is read only up to the limit, it may be much larger than the compressed one.
+		decompressed, _ := io.ReadAll(io.LimitReader(reader, traceBodyLimit+1))
+		body = decompressed
+	}
+	if len(body) > traceBodyLimit {
+		return redact(string(body[:traceBodyLimit])) + "… (truncated)"
+	}
+
+	return redact(string(body))
+}
+
+// redact replaces the API key in the text, e.g. in a URL or a body echoed by a proxy.
+func redact(text string) string {
+	if apiKey == "" {
+		return text
+	}
+
+	return strings.ReplaceAll(text, apiKey, redacted)
+}
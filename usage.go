@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+func runUsage(args []string) {
+	fs := newCommandFlagSet("usage")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Show the items imported in the current billing period, the quota left and the rate limits of the API key,
+	so the consumption can be monitored without the web console.
+
+	Options:
+		--help		display this help and exit
+
+	Example:
+		customs usage --api-key "yourApiKey"
+
+`)
+
+		os.Exit(0)
+	}
+
+	setupRun()
+	usage, err := newAPIClient(url, apiKey).GetUsage(context.Background())
+	if errors.Is(err, customs.ErrNotSupported) {
+		fatal("the server doesn't report the usage")
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	printUsage(usage)
+}
+
+// printUsage prints the usage of the account, e.g.
+//
+//	Billing period: 2024-05-01 00:00:00 CEST - 2024-05-31 23:59:59 CEST
+//	  Items imported                                  1200
+//	  Quota                                           5000  24.0%
+//	  Items left                                      3800
+//	Rate limits:
+//	  60 requests per 1m, 58 left
+func printUsage(usage *UsageResponse) {
+	if !usage.PeriodStart.IsZero() {
+		fmt.Println(trf("Billing period: %s - %s", formatTime(usage.PeriodStart), formatTime(usage.PeriodEnd)))
+	}
+	fmt.Printf("  %-45s %6d\n", tr("Items imported"), usage.ItemsUsed)
+	if usage.ItemsQuota != nil {
+		used := 0.0
+		if *usage.ItemsQuota > 0 {
+			used = 100 * float64(usage.ItemsUsed) / float64(*usage.ItemsQuota)
+		}
+		fmt.Printf("  %-45s %6d %6.1f%%\n", tr("Quota"), *usage.ItemsQuota, used)
+	} else {
+		fmt.Printf("  %-45s %6s\n", tr("Quota"), tr("none"))
+	}
+	if usage.RemainingItems != nil {
+		fmt.Printf("  %-45s %6d\n", tr("Items left"), *usage.RemainingItems)
+	}
+
+	if len(usage.RateLimits) == 0 {
+		return
+	}
+	fmt.Println(tr("Rate limits:"))
+	for _, limit := range usage.RateLimits {
+		line := trf("%d requests per %s", limit.Limit, limit.Window)
+		if limit.Remaining != nil {
+			line += ", " + trf("%d left", *limit.Remaining)
+		}
+		fmt.Printf("  %s\n", line)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// version, commit and buildDate of the CLI are set at the build time, e.g. with
+// -ldflags "-X main.version=v1.2.3 -X main.commit=abc1234 -X main.buildDate=2024-05-01T10:00:00Z".
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// userAgent identifies the CLI version and the platform to the server.
+func userAgent() string {
+	return fmt.Sprintf("customs-cli/%s (%s/%s)", version, runtime.GOOS, runtime.GOARCH)
+}
+
+// buildMetadata returns the commit and the build date of the CLI. Without the build flags, they are taken from the
+// version control information Go embeds in the binary.
+func buildMetadata() (string, string) {
+	revision, date := commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && revision == "":
+				revision = setting.Value
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			}
+		}
+	}
+	if revision == "" {
+		revision = "unknown"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+
+	return revision, date
+}
+
+func printVersion() {
+	revision, date := buildMetadata()
+	fmt.Printf("customs %s\n", version)
+	fmt.Println(trf("commit: %s", revision))
+	fmt.Println(trf("built: %s", date))
+	fmt.Printf("%s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// checkServerVersion prints the version of the server and warns if the server requires a newer CLI.
+func checkServerVersion(ctx context.Context) error {
+	server, err := newAPIClient(url, apiKey).GetVersion(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println(trf("server: %s (%s)", server.Version, url))
+	if server.MinimumCLIVersion == "" {
+		return nil
+	}
+	if cmp, ok := compareVersions(version, server.MinimumCLIVersion); !ok {
+		warnf("the server requires the CLI version %s or newer, the version of this build (%s) can't be compared", server.MinimumCLIVersion, version)
+	} else if cmp < 0 {
+		warnf("the server requires the CLI version %s or newer, please update the CLI from %s", server.MinimumCLIVersion, version)
+	}
+
+	return nil
+}
+
+// compareVersions compares the semantic versions, e.g. "v1.2.3" and "1.10.0", by their major, minor and patch numbers.
+// It reports false if one of them is not a release version, e.g. "dev".
+func compareVersions(a, b string) (int, bool) {
+	va, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	vb, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+
+	return 0, true
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var parsed [3]int
+	v = strings.TrimPrefix(v, "v")
+	// The pre-release and the build metadata, e.g. "1.2.3-rc.1", don't take part in the comparison.
+	v, _, _ = strings.Cut(v, "-")
+	v, _, _ = strings.Cut(v, "+")
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return parsed, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+
+	return parsed, true
+}
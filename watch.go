@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/drotsolutions/customs-cli/customs"
+)
+
+const defaultWatchInterval = 10 * time.Second
+
+const (
+	// maxWatchAttempts is the number of the imports of a watched file that fail on the network or the server, after
+	// which the file is moved to the failed files.
+	maxWatchAttempts = 5
+	// watchRetryWait is the wait before the second attempt of a watched file, it doubles with every attempt.
+	watchRetryWait    = time.Minute
+	maxWatchRetryWait = 30 * time.Minute
+)
+
+// watchedFile is the state of a file of the watched directory at the previous scan. A file is imported once it stays
+// the same between two scans, so the files still being copied to the directory are not imported.
+type watchedFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// retriedFile is a watched file whose import failed on the network or the server. The imports the server accepted
+// are awaited by the next attempt instead of being sent again, only the imports that weren't sent are sent.
+type retriedFile struct {
+	file     watchedFile
+	sent     *sentImports
+	attempts int
+	next     time.Time // the next attempt isn't made before
+}
+
+func runWatch(args []string) {
+	var interval time.Duration
+	fs := newCommandFlagSet("watch")
+	fs.Var(newDurationValue(defaultWatchInterval, &interval), "interval", "")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Watch the directory and import every new input file as it arrives, until the command is stopped with Ctrl+C or SIGTERM.
+	The imported files are moved to the "done" subdirectory of the watched directory together with their results,
+	the failed files are moved to the "failed" subdirectory. A file whose import failed on the network or the server,
+	e.g. a rate limit, stays in the directory and is imported again after a growing wait, at most %d times. The imports
+	the server already accepted are awaited again instead of being sent.
+
+	Options:
+		--interval	how often the directory is checked for new files (default %s)
+		--output-dir	write the output files to the directory (default is the "done" subdirectory)
+		--output	write output to the file (default "{input}-result.xlsx"), see "customs --help" for the formats and destinations
+		--yes		answer yes to all confirmation prompts, e.g. for the inputs with more items than --confirm-items
+		--help		display this help and exit
+
+	The other options of the import are the same as in "customs --help".
+
+	Example:
+		customs watch --api-key "yourApiKey" --yes /mnt/share/suppliers
+
+`, maxWatchAttempts, defaultWatchInterval)
+
+		os.Exit(0)
+	}
+
+	dir := fs.Arg(0)
+	if dir == "" {
+		fatal("please provide the watched directory as the command argument")
+	}
+	if interval <= 0 {
+		fatal("interval flag must be positive")
+	}
+	if resume != "" {
+		fatal("resume flag can't be used with the watch command")
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		fatalf("watched directory %q is not a directory", dir)
+	}
+
+	config := setupRun()
+	if setupOutputs(config) {
+		outputs[0].Destination = inputPlaceholder + "-" + defaultOutput
+	}
+	// The results are written alongside the imported inputs by default.
+	if outputDir == "" {
+		outputDir = filepath.Join(dir, doneDir)
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		fatal(err)
+	}
+	outputs = inOutputDir(outputs, outputDir)
+	if err := requireInputPlaceholder(outputs); err != nil {
+		fatal(err)
+	}
+	if err := validateInputDirOutputs(outputs, dir); err != nil {
+		fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintln(console, trf("Watching the directory %q for new input files, press Ctrl+C to stop.", dir))
+	watched := map[string]watchedFile{}
+	retried := map[string]*retriedFile{}
+	for {
+		watchDirectory(dir, watched, retried)
+		if err := sleep(ctx, interval); err != nil {
+			fmt.Fprintln(console, trf("Stopped watching the directory %q.", dir))
+			fmt.Fprintln(console, trf("Run ID: %s", runID))
+			return
+		}
+	}
+}
+
+// watchDirectory imports the files of the directory that haven't changed since the previous scan and moves them out
+// of the directory. The files whose import failed on the network or the server are imported again once their wait
+// passes.
+func watchDirectory(dir string, watched map[string]watchedFile, retried map[string]*retriedFile) {
+	inputs, err := listInputDir(dir)
+	if err != nil {
+		// The directory can be temporarily unavailable, e.g. a network share, the next scan tries again.
+		warnf("scanning the watched directory failed: %s", err)
+		return
+	}
+
+	present := map[string]bool{}
+	for _, input := range inputs {
+		present[input] = true
+		info, err := os.Stat(input)
+		if err != nil {
+			continue
+		}
+		file := watchedFile{size: info.Size(), modTime: info.ModTime()}
+		retry := retried[input]
+		if retry != nil && retry.file != file {
+			// The file is replaced, the imports sent earlier don't have its items.
+			delete(retried, input)
+			retry = nil
+		}
+		if retry == nil {
+			if previous, ok := watched[input]; !ok || previous != file {
+				watched[input] = file
+				continue
+			}
+			delete(watched, input)
+			retry = &retriedFile{file: file, sent: &sentImports{}}
+		} else if time.Now().Before(retry.next) {
+			continue
+		}
+		retry.attempts++
+
+		if retry.attempts == 1 {
+			fmt.Fprint(console, "\n"+trf("New input file: %q", input)+"\n")
+		} else {
+			fmt.Fprint(console, "\n"+trf("Input file %q, attempt %d of %d", input, retry.attempts, maxWatchAttempts)+"\n")
+		}
+		summary, err := importInput(input, inputOutputs(outputs, input), importOptions{several: true, sent: retry.sent})
+		if err != nil {
+			fmt.Fprintln(console, trf("The input %q failed: %s", input, err))
+		} else {
+			fmt.Fprintln(console, trf("%s: %d items, %d with warnings", summary.input, summary.items, summary.warnings))
+		}
+		// The input is imported again at a later scan when the server or the network failed, it is moved to the
+		// failed files when the input itself is the cause or the attempts are used up.
+		if summary.err != nil && isTemporaryImportError(summary.err) && retry.attempts < maxWatchAttempts {
+			wait := min(watchRetryWait<<(retry.attempts-1), maxWatchRetryWait)
+			retry.next = time.Now().Add(wait)
+			retried[input] = retry
+			warnf("input %q is kept in the watched directory and imported again in %s: %s", input, wait, summary.err)
+			continue
+		}
+		delete(retried, input)
+		if err = moveInput(input, summary.err == nil); err != nil {
+			warnf("input %q is not moved out of the input directory: %s", input, err)
+		}
+	}
+	for input := range watched {
+		if !present[input] {
+			delete(watched, input)
+		}
+	}
+	for input := range retried {
+		if !present[input] {
+			delete(retried, input)
+		}
+	}
+}
+
+// isTemporaryImportError reports whether the import failed for a reason other than the input, which can go away by
+// itself: a network failure, a rate limit or a server error, an import not processed in time, or a rejected API key.
+func isTemporaryImportError(err error) bool {
+	var statusErr *customs.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+
+	return isTransientError(err) ||
+		errors.Is(err, ErrNotProcessed) ||
+		errors.Is(err, customs.ErrUnauthorized) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
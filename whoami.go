@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func runWhoami(args []string) {
+	fs := newCommandFlagSet("whoami")
+	_ = fs.Parse(args)
+	if help {
+		printHelp(`	Print the account of the API key with its customs territories and permissions, e.g. to check which customer's
+	account a key belongs to.
+
+	Options:
+		--help		display this help and exit
+
+	Example:
+		customs whoami --api-key "yourApiKey"
+
+`)
+
+		os.Exit(0)
+	}
+
+	setupRun()
+	account, err := getAccount(context.Background(), url, apiKey)
+	if err != nil {
+		fatal(err)
+	}
+
+	printAccount(account)
+}
+
+// printAccount prints the account and the API key, the values the server doesn't return are left out.
+func printAccount(account *AccountResponse) {
+	printField := func(label, value string) {
+		if value != "" {
+			fmt.Printf("%-22s %s\n", tr(label)+":", value)
+		}
+	}
+
+	printField("Server", url)
+	name := account.Name
+	if account.ID != "" {
+		name = strings.TrimSpace(fmt.Sprintf("%s (%s)", account.Name, account.ID))
+	}
+	printField("Account", name)
+	printField("API key", account.APIKeyName)
+	printField("Customs territories", strings.ToUpper(strings.Join(account.CustomsTerritories, ", ")))
+	printField("Permissions", strings.Join(account.Permissions, ", "))
+}